@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/fun7257/sgv/internal/alias"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var aliasCmd = &cobra.Command{
+	Use:   "alias",
+	Short: "Manage names for Go versions",
+	Long: `Manage your own names for Go versions.
+
+An alias can stand for an exact version, a version constraint, a symbolic version
+(stable, oldstable or <major>.latest) or a toolchain built from source. Aliases are
+stored in ~/.sgv/aliases.json and can be used anywhere a version is accepted.
+
+Without a subcommand, all aliases are listed.
+
+Examples:
+  sgv alias set prod 1.22.6
+  sgv alias set legacy '~1.20'
+  sgv alias set edge stable
+  sgv prod                  # Switch to go1.22.6
+  sgv alias rm legacy`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		listAliases()
+	},
+}
+
+var aliasListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all aliases",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		listAliases()
+	},
+}
+
+var aliasSetCmd = &cobra.Command{
+	Use:   "set <name> <version>",
+	Short: "Create or change an alias",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		name, target := args[0], args[1]
+		if isCommandName(name) {
+			fmt.Fprintf(os.Stderr, "Error: invalid alias name %q: it is an sgv command.\n", name)
+			os.Exit(1)
+		}
+
+		if err := alias.Set(name, target); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Alias %s now stands for %s.\n", color.GreenString(name), target)
+	},
+}
+
+var aliasRmCmd = &cobra.Command{
+	Use:     "rm <name>...",
+	Aliases: []string{"remove"},
+	Short:   "Remove aliases",
+	Args:    cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		failed := false
+		for _, name := range args {
+			if err := alias.Remove(name); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				failed = true
+				continue
+			}
+			fmt.Printf("Removed alias %s.\n", name)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+// listAliases prints all aliases sorted by name.
+func listAliases() {
+	aliases, err := alias.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(aliases) == 0 {
+		fmt.Println("No aliases defined. Create one with 'sgv alias set <name> <version>'.")
+		return
+	}
+
+	names := make([]string, 0, len(aliases))
+	width := 0
+	for name := range aliases {
+		names = append(names, name)
+		width = max(width, len(name))
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		fmt.Printf("%-*s -> %s\n", width, name, aliases[name])
+	}
+}
+
+// isCommandName reports whether name is an sgv command, which would shadow an alias
+// of that name on the command line.
+func isCommandName(name string) bool {
+	if name == "help" || name == "completion" {
+		return true
+	}
+	for _, c := range rootCmd.Commands() {
+		if c.Name() == name || c.HasAlias(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	aliasCmd.AddCommand(aliasListCmd, aliasSetCmd, aliasRmCmd)
+	rootCmd.AddCommand(aliasCmd)
+}
@@ -3,7 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
-	"slices"
+	"runtime"
 	"strings"
 
 	"github.com/fun7257/sgv/internal/version"
@@ -14,7 +14,10 @@ import (
 var autoCmd = &cobra.Command{
 	Use:   "auto",
 	Short: "Automatically switch to the most suitable Go version for the current project",
-	Long:  `If the current directory is a Go project, this command automatically switches to the Go version specified in go.mod, or the closest compatible installed version. If no compatible version is found, it prompts the user to install one.`,
+	Long: `If the current directory is a Go project, this command automatically switches to a Go version
+suitable for go.mod: the newest installed patch release of the required minor version that is at
+least the required version (e.g., go1.22.6 for "go 1.22.1"). If none is installed, it offers to
+install the newest suitable release. Nothing happens if the active version is already suitable.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		goModVersion, err := findGoModVersion()
 		if err != nil {
@@ -33,32 +36,38 @@ var autoCmd = &cobra.Command{
 			os.Exit(1)
 		}
 
-		localVersions, err := version.GetLocalVersions()
+		// go.mod names a minimum: any later patch release of the same minor version will do
+		constraint, err := version.ParseConstraint("~" + strings.TrimPrefix(goModVersion, "go"))
 		if err != nil {
-			fmt.Fprintf(os.Stderr, "Error getting local versions: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 			os.Exit(1)
 		}
 
-		suitableVersion := goModVersion
-		suitableVersionSource := "remote" // Assume remote initially
-
-		// Check if the go.mod version is installed locally
-		if slices.Contains(localVersions, goModVersion) {
-			suitableVersionSource = "local"
-		}
-
 		currentActiveVersion, err := version.GetCurrentVersion()
 		if err != nil {
 			// If we can't get current version, proceed with suitableVersion
 			currentActiveVersion = ""
 		}
+		if currentActiveVersion != "" && (currentActiveVersion == goModVersion || constraint.Match(currentActiveVersion)) {
+			return // No output, no switch needed
+		}
 
-		if suitableVersion != "" {
-			// If suitableVersion is the same as currentActiveVersion, no switch needed.
-			if currentActiveVersion != "" && suitableVersion == currentActiveVersion {
-				return // No output, no switch needed
+		// Prefer the newest suitable installed version, then the newest suitable release
+		suitableVersion, err := version.ResolveInstalled(constraint)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error getting local versions: %v\n", err)
+			os.Exit(1)
+		}
+		suitableVersionSource := "local"
+		if suitableVersion == "" {
+			suitableVersionSource = "remote"
+			if suitableVersion, err = version.ResolveRemote(constraint, runtime.GOOS, runtime.GOARCH); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+				suitableVersion = ""
 			}
+		}
 
+		if suitableVersion != "" {
 			fmt.Printf("go.mod requires Go version: %s\n", goModVersion)
 			msg := fmt.Sprintf("Found suitable version: %s.", suitableVersion)
 			if suitableVersionSource == "remote" {
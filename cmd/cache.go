@@ -0,0 +1,145 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/cache"
+	"github.com/fun7257/sgv/internal/config"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	pruneOlderThan string
+	cachePartial   bool
+)
+
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the download cache",
+	Long: `Manage the cache of downloaded Go archives and the remote version index.
+
+Downloaded archives are kept in ~/.sgv/cache/downloads, so reinstalling a removed
+version does not download it again.
+
+Examples:
+  sgv cache list                    # List cached files
+  sgv cache size                    # Show the total cache size
+  sgv cache prune --older-than 30d  # Remove files not used in the last 30 days
+  sgv cache clear                   # Remove everything but partial downloads
+
+Partial downloads are kept by prune and clear, as another sgv process may still be
+writing to them; pass --partial to remove them as well.`,
+}
+
+var cacheListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List cached files",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := cache.List()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error listing cache: %v\n", err)
+			os.Exit(1)
+		}
+
+		if len(entries) == 0 {
+			fmt.Println("The cache is empty.")
+			return
+		}
+
+		fmt.Printf("Cached files in %s:\n", config.CacheDir)
+		for _, e := range entries {
+			name := e.Name
+			switch e.Kind {
+			case cache.KindArchive:
+				kind, checksum := "sha256", e.Checksum
+				if h, ok := strings.CutPrefix(checksum, "h1:"); ok {
+					kind, checksum = "h1", h
+				}
+				name = fmt.Sprintf("%s (%s %.12s)", e.Name, kind, checksum)
+			case cache.KindPartial:
+				name = fmt.Sprintf("%s (partial download)", e.Name)
+			case cache.KindIndex:
+				name = fmt.Sprintf("%s (version index)", e.Name)
+			}
+			fmt.Printf("  %-10s  %s  %s\n", formatSize(e.Size), e.ModTime.Format("2006-01-02 15:04"), name)
+		}
+	},
+}
+
+var cacheSizeCmd = &cobra.Command{
+	Use:   "size",
+	Short: "Show the total size of the cache",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		size, err := cache.Size()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing cache size: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("%s\t%s\n", formatSize(size), config.CacheDir)
+	},
+}
+
+var cachePruneCmd = &cobra.Command{
+	Use:   "prune",
+	Short: "Remove cached files that have not been used recently",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		age, err := parseAge(pruneOlderThan)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		removed, err := cache.Prune(age, cachePartial)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error pruning cache: %v\n", err)
+			os.Exit(1)
+		}
+
+		var freed int64
+		for _, e := range removed {
+			fmt.Printf("Removed %s\n", e.Name)
+			freed += e.Size
+		}
+		fmt.Printf("Pruned %d file(s), freed %s.\n", len(removed), formatSize(freed))
+	},
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove everything but partial downloads from the cache",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		before, err := cache.Size()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing cache size: %v\n", err)
+			os.Exit(1)
+		}
+
+		if err := cache.Clear(cachePartial); err != nil {
+			fmt.Fprintf(os.Stderr, "Error clearing cache: %v\n", err)
+			os.Exit(1)
+		}
+		after, err := cache.Size()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error computing cache size: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Cache cleared, freed %s.\n", formatSize(before-after))
+	},
+}
+
+func init() {
+	cachePruneCmd.Flags().StringVar(&pruneOlderThan, "older-than", "30d", "Remove files not used for this long (e.g., 72h, 30d, 2w)")
+	for _, c := range []*cobra.Command{cachePruneCmd, cacheClearCmd} {
+		c.Flags().BoolVar(&cachePartial, "partial", false, "Also remove partial downloads, even if an install may still be writing to them")
+	}
+
+	cacheCmd.AddCommand(cacheListCmd, cacheSizeCmd, cachePruneCmd, cacheClearCmd)
+	rootCmd.AddCommand(cacheCmd)
+}
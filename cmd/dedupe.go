@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/dedupe"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/spf13/cobra"
+)
+
+var dedupeCmd = &cobra.Command{
+	Use:   "dedupe [version...]",
+	Short: "Replace identical files across installed versions with hard links",
+	Long: `Save disk space by replacing files that are identical across installed Go versions
+with hard links to a single copy. Patch releases of the same minor version share
+most of their files (src/, doc/, test/), so this typically frees most of the space
+taken by every additional patch release.
+
+Without arguments all installed versions are deduplicated; otherwise only the given
+versions (or all installed releases of the given major versions).
+
+Hard links are reference counted, so 'sgv rm' keeps removing versions safely: the
+data of a shared file is only freed when the last version using it is removed. Do
+not edit files inside installed versions, as the change would show up in every
+version sharing the file.
+
+To deduplicate new installations automatically, use 'sgv install --dedupe' or set
+SGV_DEDUPE=true.
+
+Examples:
+  sgv dedupe              # All installed versions
+  sgv dedupe 1.22         # All installed 1.22.x releases
+  sgv dedupe 1.21.13 1.22.6`,
+	Run: func(cmd *cobra.Command, args []string) {
+		installedVersions, err := version.GetLocalVersions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: could not list installed Go versions: %v\n", err)
+			os.Exit(1)
+		}
+
+		versions := installedVersions
+		if len(args) > 0 {
+			versions = nil
+			installed := make(map[string]bool, len(installedVersions))
+			for _, v := range installedVersions {
+				installed[v] = true
+			}
+			for _, v := range findMatchingVersions(args, installedVersions) {
+				if !installed[v] {
+					fmt.Fprintf(os.Stderr, "Info: Go version %s is not installed. Skipping.\n", v)
+					continue
+				}
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) < 2 {
+			fmt.Println("Nothing to deduplicate: at least two installed versions are needed.")
+			return
+		}
+
+		fmt.Printf("Deduplicating %s...\n", strings.Join(versions, ", "))
+		result, err := dedupe.Versions(versions)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error deduplicating: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("Linked %d file(s), freed %s.\n", result.Linked, formatSize(result.Saved))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(dedupeCmd)
+}
@@ -0,0 +1,82 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/usage"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var duSort string
+
+var duCmd = &cobra.Command{
+	Use:   "du",
+	Short: "Show the disk space used by installed versions, env files and the cache",
+	Long: `Show the disk space used by sgv: the size of every installed Go version, the
+per-version env files and the download cache, followed by the total.
+
+Examples:
+  sgv du              # Versions in version order
+  sgv du --sort size  # Largest versions first`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if duSort != "version" && duSort != "size" {
+			fmt.Fprintf(os.Stderr, "Error: invalid --sort %q, must be 'version' or 'size'.\n", duSort)
+			os.Exit(1)
+		}
+
+		report, err := usage.Collect()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error measuring disk usage: %v\n", err)
+			os.Exit(1)
+		}
+		if duSort == "size" {
+			usage.SortBySize(report.Versions)
+			usage.SortBySize(report.EnvFiles)
+		}
+
+		currentVersion, _ := version.GetCurrentVersion()
+
+		fmt.Printf("Go versions in %s:\n", config.VersionsDir)
+		if len(report.Versions) == 0 {
+			fmt.Println("  (none installed)")
+		}
+		var versionsTotal int64
+		for _, e := range report.Versions {
+			versionsTotal += e.Size
+			if e.Name == currentVersion {
+				fmt.Printf("  %10s  %s %s\n", formatSize(e.Size), e.Name, color.GreenString("<- current"))
+			} else {
+				fmt.Printf("  %10s  %s\n", formatSize(e.Size), e.Name)
+			}
+		}
+
+		var envTotal int64
+		if len(report.EnvFiles) > 0 {
+			fmt.Println("\nEnv files:")
+			for _, e := range report.EnvFiles {
+				envTotal += e.Size
+				fmt.Printf("  %10s  %s\n", formatSize(e.Size), e.Name)
+			}
+		}
+
+		fmt.Println()
+		fmt.Printf("  %10s  versions (%d)\n", formatSize(versionsTotal), len(report.Versions))
+		fmt.Printf("  %10s  env files (%d)\n", formatSize(envTotal), len(report.EnvFiles))
+		if report.Shared > 0 {
+			fmt.Printf("  %10s  shared between versions through hard links\n", "-"+formatSize(report.Shared))
+		}
+		fmt.Printf("  %10s  download cache (%s)\n", formatSize(report.Cache), config.CacheDir)
+		fmt.Printf("  %10s  total\n", formatSize(report.Total()))
+	},
+}
+
+func init() {
+	duCmd.Flags().StringVar(&duSort, "sort", "version", "Sort versions and env files by 'version' or 'size' (largest first)")
+	rootCmd.AddCommand(duCmd)
+}
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/spf13/cobra"
+)
+
+var infoCmd = &cobra.Command{
+	Use:   "info [version]",
+	Short: "Show where an installed Go version came from",
+	Long: `Show the metadata recorded when a Go version was installed: the install method,
+the download source, mirror, proxy, local path or git repository, the archive and
+its checksum, the platform, the install time and the sgv version that installed
+it, followed by the contents of its go/VERSION file.
+
+Without an argument the active version is shown.
+
+Examples:
+  sgv info
+  sgv info 1.22.1`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var versionStr string
+		if len(args) == 0 {
+			current, err := version.GetCurrentVersion()
+			if err != nil {
+				fmt.Fprintln(os.Stderr, "Error: no active Go version. Give a version, e.g. 'sgv info 1.22.1'.")
+				os.Exit(1)
+			}
+			versionStr = current
+		} else {
+			expanded, err := expandVersionArg(args[0], runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			versionStr = expanded
+			if !strings.HasPrefix(versionStr, "go") {
+				versionStr = "go" + versionStr
+			}
+		}
+
+		goroot := filepath.Join(config.VersionsDir, versionStr, "go")
+		if _, err := os.Stat(goroot); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Go version %s is not installed.\n", versionStr)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Version:      %s\n", versionStr)
+		fmt.Printf("GOROOT:       %s\n", goroot)
+
+		meta, err := metadata.Load(metadata.Path(versionStr))
+		switch {
+		case os.IsNotExist(err):
+			fmt.Println("No install metadata: this version was installed before sgv recorded it.")
+		case err != nil:
+			fmt.Fprintf(os.Stderr, "Error reading install metadata: %v\n", err)
+			os.Exit(1)
+		default:
+			printMetadata(meta)
+		}
+
+		data, err := os.ReadFile(filepath.Join(goroot, "VERSION"))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error reading go/VERSION: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("\ngo/VERSION:")
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			fmt.Printf("  %s\n", line)
+		}
+	},
+}
+
+// printMetadata prints the recorded install metadata, skipping empty fields.
+func printMetadata(meta *metadata.Metadata) {
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Printf("%-13s %s\n", name+":", value)
+		}
+	}
+
+	field("Method", meta.Method)
+	source := meta.Source
+	if meta.Cached {
+		source += " (from the download cache, verified against this source)"
+	}
+	field("Source", source)
+	field("Archive", meta.Filename)
+	field("Checksum", meta.Checksum)
+	field("Ref", meta.Ref)
+	field("Commit", meta.Commit)
+	field("Platform", meta.OS+"/"+meta.Arch)
+	field("Installed at", meta.InstalledAt.Local().Format(time.RFC3339))
+	field("Installed by", "sgv "+meta.SgvVersion)
+}
+
+func init() {
+	rootCmd.AddCommand(infoCmd)
+}
@@ -0,0 +1,341 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/installer"
+	"github.com/fun7257/sgv/internal/progress"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	installFrom      string
+	installSource    string
+	installRepo      string
+	installBootstrap string
+	installGoProxy   bool
+	installJobs      int
+	installDedupe    bool
+	installPlatform  string
+	installDest      string
+)
+
+var installCmd = &cobra.Command{
+	Use:   "install [<version>...] [--from <path> | --source <git-ref> | --dest <dir>]",
+	Short: "Install Go versions without switching to them",
+	Long: `Install one or more Go versions without switching to them.
+
+Versions are given as exact releases (1.22.6) or as major versions (1.22 or
+1.22.x), which install the latest stable patch release. Several versions are
+downloaded concurrently (see --jobs), and a summary lists the outcome for every
+version. The command exits with a non-zero status if any installation failed.
+
+Use --from to install from a Go archive (.tar.gz) or an already unpacked Go
+distribution (a GOROOT, or a directory containing one as "go"). The version is
+read from the distribution's VERSION file, so no network access is required.
+
+Use 'tip' or --source to build Go from the Go git repository with make.bash. The
+newest installed release (or --bootstrap) is used as GOROOT_BOOTSTRAP, and the
+result is installed as gotip-<shortsha>, which can be listed, switched to and
+removed like any other version.
+
+Use --goproxy to install releases (Go 1.21 and later) from the golang.org/toolchain
+module served by a Go module proxy, the same zip the go command uses for toolchain
+switches. The proxy is taken from SGV_GOPROXY or GOPROXY (default
+https://proxy.golang.org), and the zip is verified against its go.sum hash from the
+checksum database (SGV_GOSUMDB or GOSUMDB, default sum.golang.org).
+
+Use --dest to unpack releases into a directory of your choice instead, e.g. to
+prepare an offline bundle or a container build context. With --platform os/arch
+the release for another platform is fetched. Each release ends up in
+<dest>/<version>.<os>-<arch>/go, next to its manifest and install.json, and is not
+registered with sgv: it cannot be switched to and is not listed by 'sgv list'.
+
+Examples:
+  sgv install 1.21.13 1.22.6 1.23.2
+  sgv install 1.21 1.22.x --jobs 2
+  sgv install --from ./go1.22.1.linux-amd64.tar.gz
+  sgv install --from /opt/go
+  sgv install tip
+  sgv install --source release-branch.go1.23
+  sgv install --source 3f5a6b7c --repo ~/src/go --bootstrap 1.22.6
+  sgv install --goproxy 1.22.1
+  sgv install 1.22.6 --platform linux/arm64 --dest ./bundle`,
+	Run: func(cmd *cobra.Command, args []string) {
+		buildFromSource := (len(args) == 1 && args[0] == "tip") || installSource != ""
+
+		switch {
+		case installPlatform != "" && installDest == "":
+			fmt.Fprintln(os.Stderr, "Error: --platform requires --dest, since versions for other platforms cannot be switched to.")
+			os.Exit(1)
+		case installDest != "" && (buildFromSource || installFrom != "" || installDedupe):
+			fmt.Fprintln(os.Stderr, "Error: --dest cannot be combined with --from, --source, --dedupe or 'tip'.")
+			os.Exit(1)
+		case installDest != "":
+			installToDest(args)
+			return
+		case buildFromSource && (installFrom != "" || installGoProxy):
+			fmt.Fprintln(os.Stderr, "Error: building from source cannot be combined with --from or --goproxy.")
+			os.Exit(1)
+		case installSource != "" && len(args) > 0:
+			fmt.Fprintln(os.Stderr, "Error: --source cannot be combined with version arguments.")
+			os.Exit(1)
+		case installFrom != "" && (len(args) > 0 || installGoProxy):
+			fmt.Fprintln(os.Stderr, "Error: --from cannot be combined with version arguments or --goproxy.")
+			os.Exit(1)
+		case len(args) > 0 && !buildFromSource:
+			installVersions(args)
+			return
+		case installGoProxy:
+			fmt.Fprintln(os.Stderr, "Error: --goproxy requires a release version, e.g. 'sgv install --goproxy 1.22.1'.")
+			os.Exit(1)
+		}
+
+		var (
+			installedVersion string
+			err              error
+		)
+		switch {
+		case buildFromSource:
+			installedVersion, err = installer.InstallFromSource(installer.SourceOptions{
+				Ref:       installSource,
+				Repo:      installRepo,
+				Bootstrap: installBootstrap,
+			})
+		case installFrom != "":
+			installedVersion, err = installFromPath(installFrom)
+		default:
+			fmt.Fprintln(os.Stderr, "Error: nothing to install. Give one or more versions, 'tip', --from <archive|directory> or --source <git-ref>.")
+			os.Exit(1)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error installing Go: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Successfully installed Go version %s. Use 'sgv %s' to switch to it.\n", installedVersion, strings.TrimPrefix(installedVersion, "go"))
+	},
+}
+
+// installResult is the outcome of installing a single version.
+type installResult struct {
+	version string
+	skipped bool // Already installed
+	err     error
+}
+
+// installVersions installs the versions (or major versions) named in args with up
+// to installJobs concurrent installs, prints a summary and exits non-zero if any failed.
+func installVersions(args []string) {
+	if installJobs < 1 {
+		fmt.Fprintln(os.Stderr, "Error: --jobs must be at least 1.")
+		os.Exit(1)
+	}
+
+	var (
+		results []installResult
+		pending []int // Indexes into results that still need to be installed
+	)
+	seen := make(map[string]bool)
+	for _, arg := range args {
+		versionStr, err := resolveInstallTarget(arg, runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			results = append(results, installResult{version: arg, err: err})
+			continue
+		}
+		if seen[versionStr] {
+			continue
+		}
+		seen[versionStr] = true
+
+		result := installResult{version: versionStr}
+		if _, err := os.Stat(filepath.Join(config.VersionsDir, versionStr)); err == nil {
+			result.skipped = true
+		} else {
+			pending = append(pending, len(results))
+		}
+		results = append(results, result)
+	}
+
+	opts := installer.Options{GoProxy: installGoProxy, Dedupe: installDedupe}
+	if len(pending) == 1 {
+		// A single install keeps the regular, detailed output
+		i := pending[0]
+		results[i].err = installer.InstallWith(results[i].version, opts)
+	} else if len(pending) > 1 {
+		installConcurrently(results, pending, opts)
+	}
+
+	failed := 0
+	fmt.Println("\nSummary:")
+	for _, r := range results {
+		switch {
+		case r.err != nil:
+			failed++
+			fmt.Printf("  %-12s %s: %v\n", r.version, color.RedString("failed"), r.err)
+		case r.skipped:
+			fmt.Printf("  %-12s already installed\n", r.version)
+		default:
+			fmt.Printf("  %-12s %s\n", r.version, color.GreenString("installed"))
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "Error: %d of %d installation(s) failed.\n", failed, len(results))
+		os.Exit(1)
+	}
+	fmt.Println("Use 'sgv <version>' to switch to an installed version.")
+}
+
+// installConcurrently installs results[i] for every i in pending with a pool of
+// installJobs workers, showing one progress bar per version.
+func installConcurrently(results []installResult, pending []int, opts installer.Options) {
+	multi := progress.NewMulti(os.Stdout)
+	bars := make(map[int]*progress.Bar, len(pending))
+	for _, i := range pending {
+		bars[i] = multi.Add(results[i].version)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(installJobs, len(pending)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				bar := bars[i]
+				opts := opts
+				opts.Output = bar.Status()
+				opts.Progress = bar.Start
+
+				results[i].err = installer.InstallWith(results[i].version, opts)
+				if results[i].err != nil {
+					bar.Done("failed")
+				} else {
+					bar.Done("installed")
+				}
+			}
+		}()
+	}
+
+	for _, i := range pending {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	multi.Stop()
+}
+
+// installToDest unpacks the versions named in args for installPlatform (default:
+// this platform) into installDest, one after another, and exits non-zero if any failed.
+func installToDest(args []string) {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "Error: --dest requires one or more release versions, e.g. 'sgv install 1.22.6 --dest ./bundle'.")
+		os.Exit(1)
+	}
+
+	goos, goarch := runtime.GOOS, runtime.GOARCH
+	if installPlatform != "" {
+		var ok bool
+		goos, goarch, ok = strings.Cut(installPlatform, "/")
+		if !ok || goos == "" || goarch == "" || strings.Contains(goarch, "/") {
+			fmt.Fprintf(os.Stderr, "Error: invalid --platform %q, must be os/arch, e.g. linux/arm64.\n", installPlatform)
+			os.Exit(1)
+		}
+	}
+
+	opts := installer.Options{GoProxy: installGoProxy}
+	seen := make(map[string]bool)
+	failed := 0
+	for _, arg := range args {
+		versionStr, err := resolveInstallTarget(arg, goos, goarch)
+		if err == nil && seen[versionStr] {
+			continue
+		}
+		var dir string
+		if err == nil {
+			seen[versionStr] = true
+			dir, err = installer.InstallTo(versionStr, goos, goarch, installDest, opts)
+		}
+		if err != nil {
+			failed++
+			fmt.Fprintf(os.Stderr, "Error installing %s for %s/%s: %v\n", arg, goos, goarch, err)
+			continue
+		}
+		fmt.Printf("Unpacked %s for %s/%s into %s\n", versionStr, goos, goarch, dir)
+	}
+
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// resolveInstallTarget turns an install argument into a version name. Aliases and
+// symbolic versions are expanded first, exact versions ("1.22.6") are used as is,
+// while major versions ("1.22" or "1.22.x") resolve to their latest stable patch
+// release for goos/goarch and other constraints to the newest matching release.
+func resolveInstallTarget(arg, goos, goarch string) (string, error) {
+	expanded, err := expandVersionArg(arg, goos, goarch)
+	if err != nil {
+		return "", err
+	}
+	if version.IsDevel(expanded) {
+		return expanded, nil
+	}
+	versionStr := expanded
+	if !strings.HasPrefix(versionStr, "go") {
+		versionStr = "go" + versionStr
+	}
+
+	major, wildcard := strings.CutSuffix(strings.TrimPrefix(versionStr, "go"), ".x")
+	if wildcard || version.MajorVersion(versionStr) == major {
+		return version.LatestPatch(major, goos, goarch)
+	}
+
+	if !version.IsValid(versionStr) {
+		// Other constraints (e.g., an alias for "~1.21") pick the newest matching release
+		if c, err := version.ParseConstraint(expanded); err == nil {
+			return version.ResolveRemote(c, goos, goarch)
+		}
+		return "", fmt.Errorf("invalid Go version %q", arg)
+	}
+	if !isGoVersionSupported(versionStr) {
+		return "", fmt.Errorf("Go version %s is not supported. sgv only supports Go 1.13 and later", versionStr)
+	}
+	return versionStr, nil
+}
+
+// installFromPath installs from a local archive or unpacked Go distribution.
+func installFromPath(path string) (string, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("cannot access %s: %w", path, err)
+	}
+
+	if fi.IsDir() {
+		return installer.InstallFromDir(path)
+	}
+	return installer.InstallFromArchive(path)
+}
+
+func init() {
+	installCmd.Flags().StringVar(&installFrom, "from", "", "Install from a local .tar.gz archive or an unpacked Go distribution")
+	installCmd.Flags().StringVar(&installSource, "source", "", "Build and install Go from this git ref (branch, tag or commit)")
+	installCmd.Flags().StringVar(&installRepo, "repo", "", "Go repository to build from: a local checkout or a remote URL (default "+installer.DefaultGoRepo+")")
+	installCmd.Flags().StringVar(&installBootstrap, "bootstrap", "", "Installed Go version to use as GOROOT_BOOTSTRAP (default: newest installed release)")
+	installCmd.Flags().BoolVar(&installGoProxy, "goproxy", false, "Install the given releases from the golang.org/toolchain module on the Go module proxy")
+	installCmd.Flags().BoolVar(&installDedupe, "dedupe", false, "Hard link files identical to installed patch releases of the same minor version (see 'sgv dedupe')")
+	installCmd.Flags().StringVar(&installPlatform, "platform", "", "Platform (os/arch) to fetch the releases for, e.g. linux/arm64; requires --dest")
+	installCmd.Flags().StringVar(&installDest, "dest", "", "Unpack the releases into this directory instead of installing them")
+	installCmd.Flags().IntVarP(&installJobs, "jobs", "j", 3, "Number of versions to install concurrently")
+	rootCmd.AddCommand(installCmd)
+}
@@ -11,13 +11,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var latestUnstable bool
+
 var latestCmd = &cobra.Command{
 	Use:   "latest",
 	Short: "Install the latest Go version",
-	Long:  `Check for the latest Go version, install it if not already installed, and switch to it.`,
+	Long: `Check for the latest Go version, install it if not already installed, and switch to it.
+Use --unstable to consider prereleases (release candidates and betas) as well.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		// Get the latest version
-		latestVersion, err := version.GetLatestGoVersion()
+		getLatest := version.GetLatestGoVersion
+		if latestUnstable {
+			getLatest = version.GetLatestUnstableGoVersion
+		}
+		latestVersion, err := getLatest()
 		if err != nil {
 			fmt.Fprintf(os.Stderr, "Error getting the latest Go version: %v\n", err)
 			os.Exit(1)
@@ -78,5 +85,6 @@ var latestCmd = &cobra.Command{
 }
 
 func init() {
+	latestCmd.Flags().BoolVar(&latestUnstable, "unstable", false, "Pick the newest release including prereleases (release candidates and betas)")
 	rootCmd.AddCommand(latestCmd)
 }
@@ -63,12 +63,19 @@ var listCmd = &cobra.Command{
 	},
 }
 
-func getMajorVersion(version string) string {
-	parts := strings.Split(strings.TrimPrefix(version, "go"), ".")
+func getMajorVersion(v string) string {
+	// Toolchains built from source are grouped together
+	if version.IsDevel(v) {
+		return "gotip"
+	}
+	if major := version.MajorVersion(v); major != "" {
+		return "go" + major
+	}
+	parts := strings.Split(strings.TrimPrefix(v, "go"), ".")
 	if len(parts) >= 2 {
 		return "go" + parts[0] + "." + parts[1]
 	}
-	return version
+	return v
 }
 
 func init() {
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+
+	"github.com/fun7257/sgv/internal/mirror"
+
+	"github.com/spf13/cobra"
+)
+
+var (
+	mirrorDir       string
+	mirrorVersions  []string
+	mirrorPlatforms []string
+	mirrorAddr      string
+)
+
+var mirrorCmd = &cobra.Command{
+	Use:   "mirror",
+	Short: "Build and serve a go.dev/dl compatible download mirror",
+	Long: `Build and serve your own Go download mirror.
+
+'sgv mirror sync' downloads archives into a directory and writes an index in the
+same format as go.dev/dl/?mode=json&include=all. 'sgv mirror serve' serves that
+directory over HTTP, so SGV_DOWNLOAD_URL_PREFIX can point at it.
+
+Examples:
+  sgv mirror sync --versions 1.21.x,1.22.x --platforms linux/amd64,darwin/arm64 --dir ./mirror
+  sgv mirror serve --dir ./mirror --addr :8080
+  export SGV_DOWNLOAD_URL_PREFIX=http://mirror-host:8080/`,
+}
+
+var mirrorSyncCmd = &cobra.Command{
+	Use:   "sync",
+	Short: "Download archives into a mirror directory and update its index",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		synced, err := mirror.Sync(mirror.SyncOptions{
+			Dir:       mirrorDir,
+			Versions:  mirrorVersions,
+			Platforms: mirrorPlatforms,
+		})
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error syncing mirror: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Mirror %s is up to date with %d archive(s).\n", mirrorDir, len(synced))
+	},
+}
+
+var mirrorServeCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Serve a mirror directory over HTTP",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if _, err := os.Stat(mirrorDir); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: cannot access mirror directory: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Serving Go mirror %s on %s\n", mirrorDir, mirrorAddr)
+		if err := http.ListenAndServe(mirrorAddr, mirror.Handler(mirrorDir)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error serving mirror: %v\n", err)
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	mirrorCmd.PersistentFlags().StringVar(&mirrorDir, "dir", "mirror", "Mirror directory")
+
+	mirrorSyncCmd.Flags().StringSliceVar(&mirrorVersions, "versions", nil, "Versions to mirror, exact (1.22.6) or per minor (1.22.x)")
+	mirrorSyncCmd.Flags().StringSliceVar(&mirrorPlatforms, "platforms", []string{runtime.GOOS + "/" + runtime.GOARCH}, "Platforms to mirror as <os>/<arch>")
+	_ = mirrorSyncCmd.MarkFlagRequired("versions")
+
+	mirrorServeCmd.Flags().StringVar(&mirrorAddr, "addr", ":8080", "Address to listen on")
+
+	mirrorCmd.AddCommand(mirrorSyncCmd, mirrorServeCmd)
+	rootCmd.AddCommand(mirrorCmd)
+}
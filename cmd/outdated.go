@@ -0,0 +1,195 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"runtime"
+
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var outdatedJSON bool
+
+// outdatedEntry describes one installed release in the 'sgv outdated' report.
+type outdatedEntry struct {
+	Version      string `json:"version"`
+	Minor        string `json:"minor"`
+	Latest       string `json:"latest,omitempty"` // Newest stable release of the minor version
+	Outdated     bool   `json:"outdated"`         // The newest installed release of an outdated minor version
+	Superseded   bool   `json:"superseded"`       // A newer patch release of the minor version is installed as well
+	Supported    bool   `json:"supported"`        // The minor version is one of the two newest
+	Current      bool   `json:"current"`
+	GoModAccepts *bool  `json:"gomod_accepts,omitempty"` // Latest satisfies go.mod; unset outside a Go module
+}
+
+var outdatedCmd = &cobra.Command{
+	Use:   "outdated",
+	Short: "Report installed versions with a newer patch release",
+	Long: `Report every installed Go release with the newest stable patch release of its
+minor version, whether that minor version is still supported under the Go release
+policy (the two most recent minor versions receive fixes), and, inside a Go module,
+whether go.mod would accept the newer patch release.
+
+An older patch release kept next to a newer one (e.g., by 'sgv upgrade' without
+--prune) is reported as superseded. Toolchains built from source are not reported.
+
+Exit status, e.g. to gate CI jobs:
+  0  every installed minor version has its newest patch release
+  1  some installed minor version lacks its newest patch release
+  2  the report could not be made (e.g., the release index could not be fetched)
+
+Examples:
+  sgv outdated
+  sgv outdated --json`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		if code := runOutdated(os.Stdout, os.Stderr); code != 0 {
+			os.Exit(code)
+		}
+	},
+}
+
+// Exit statuses of 'sgv outdated'.
+const (
+	outdatedExitOutdated = 1 // A minor version lacks its newest patch release
+	outdatedExitError    = 2 // The report could not be made
+)
+
+// runOutdated writes the report to stdout and problems to stderr, and returns the
+// exit status of 'sgv outdated'.
+func runOutdated(stdout, stderr io.Writer) int {
+	installedVersions, err := version.GetLocalVersions()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error: could not list installed Go versions: %v\n", err)
+		return outdatedExitError
+	}
+	remoteVersions, err := version.GetStableGoVersions()
+	if err != nil {
+		fmt.Fprintf(stderr, "Error fetching Go versions: %v\n", err)
+		return outdatedExitError
+	}
+	goModVersion, err := findGoModVersion()
+	if err != nil {
+		fmt.Fprintf(stderr, "Warning: ignoring go.mod: %v\n", err)
+	}
+	currentVersion, _ := version.GetCurrentVersion()
+
+	entries := []outdatedEntry{}
+	anyOutdated := false
+	for _, line := range version.InstalledLines(installedVersions, remoteVersions, runtime.GOOS, runtime.GOARCH) {
+		anyOutdated = anyOutdated || line.Outdated()
+		for _, v := range line.Installed {
+			e := outdatedEntry{
+				Version:    v,
+				Minor:      line.Minor,
+				Latest:     line.Latest,
+				Outdated:   v == line.Newest() && line.Outdated(),
+				Superseded: v != line.Newest(),
+				Supported:  line.Supported,
+				Current:    v == currentVersion,
+			}
+			if goModVersion != "" && line.Latest != "" {
+				accepts := isGoVersionCompatible(line.Latest, goModVersion)
+				e.GoModAccepts = &accepts
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	if outdatedJSON {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(entries); err != nil {
+			fmt.Fprintf(stderr, "Error: %v\n", err)
+			return outdatedExitError
+		}
+	} else {
+		printOutdatedTable(stdout, entries, goModVersion)
+	}
+
+	if anyOutdated {
+		return outdatedExitOutdated
+	}
+	return 0
+}
+
+// printOutdatedTable prints entries as a table to w. The go.mod column, for the Go
+// version goModVersion the module requires, is only shown inside a Go module.
+func printOutdatedTable(w io.Writer, entries []outdatedEntry, goModVersion string) {
+	if len(entries) == 0 {
+		fmt.Fprintln(w, "No Go releases installed.")
+		return
+	}
+
+	header := []string{"VERSION", "LATEST", "STATUS", "SUPPORTED"}
+	if goModVersion != "" {
+		header = append(header, "GO.MOD ACCEPTS LATEST")
+	}
+	rows := [][]string{header}
+	for _, e := range entries {
+		name := e.Version
+		if e.Current {
+			name += " (current)"
+		}
+		latest, status := e.Latest, "up to date"
+		switch {
+		case e.Latest == "":
+			latest, status = "-", "unknown"
+		case e.Outdated:
+			status = "outdated"
+		case e.Superseded:
+			status = "superseded"
+		}
+		supported := "yes"
+		if !e.Supported {
+			supported = "no"
+		}
+		row := []string{name, latest, status, supported}
+		if goModVersion != "" {
+			accepts := "-"
+			if e.GoModAccepts != nil {
+				accepts = map[bool]string{true: "yes", false: "no"}[*e.GoModAccepts]
+			}
+			row = append(row, accepts)
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(header))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+	for r, row := range rows {
+		for i, cell := range row {
+			padded := fmt.Sprintf("%-*s", widths[i], cell)
+			if i == len(row)-1 {
+				padded = cell
+			}
+			// Colors are applied after padding so that they don't skew the columns
+			switch {
+			case r == 0:
+			case cell == "outdated" || cell == "no":
+				padded = color.YellowString(padded)
+			case i == 0 && entries[r-1].Current:
+				padded = color.GreenString(padded)
+			}
+			if i > 0 {
+				fmt.Fprint(w, "  ")
+			}
+			fmt.Fprint(w, padded)
+		}
+		fmt.Fprintln(w)
+	}
+}
+
+func init() {
+	outdatedCmd.Flags().BoolVar(&outdatedJSON, "json", false, "Print the report as JSON")
+	rootCmd.AddCommand(outdatedCmd)
+}
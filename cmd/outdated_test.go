@@ -0,0 +1,88 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// setupOutdated installs the given versions into a temporary sgv root and serves
+// releases as the release index. With releases nil, the index cannot be fetched.
+func setupOutdated(t *testing.T, installed []string, releases []string) {
+	t.Helper()
+
+	root := t.TempDir()
+	originalVersions, originalCache, originalCurrent := config.VersionsDir, config.CacheDir, config.CurrentSymlink
+	originalMirrors, originalSource := config.DownloadMirrors, config.Source
+	config.VersionsDir = filepath.Join(root, "versions")
+	config.CacheDir = filepath.Join(root, "cache")
+	config.CurrentSymlink = filepath.Join(root, "current")
+	config.Source = config.DefaultSource
+	t.Cleanup(func() {
+		config.VersionsDir, config.CacheDir, config.CurrentSymlink = originalVersions, originalCache, originalCurrent
+		config.DownloadMirrors, config.Source = originalMirrors, originalSource
+	})
+
+	for _, v := range installed {
+		if err := os.MkdirAll(filepath.Join(config.VersionsDir, v, "go"), 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var index []version.GoVersionResponse
+	for _, v := range releases {
+		index = append(index, version.GoVersionResponse{Version: v, Stable: true, Files: []version.GoVersionFile{{
+			Filename: v + "." + runtime.GOOS + "-" + runtime.GOARCH + ".tar.gz",
+			OS:       runtime.GOOS, Arch: runtime.GOARCH, Version: v, Kind: "archive",
+			SHA256: strings.Repeat("0", 64),
+		}}})
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if releases == nil {
+			http.Error(w, "unavailable", http.StatusInternalServerError)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(index)
+	}))
+	t.Cleanup(server.Close)
+	config.DownloadMirrors = []string{server.URL + "/"}
+}
+
+func TestRunOutdatedExitStatus(t *testing.T) {
+	tests := []struct {
+		name      string
+		installed []string
+		releases  []string
+		want      int
+	}{
+		{"up to date", []string{"go1.22.6", "go1.23.2"}, []string{"go1.23.2", "go1.22.6"}, 0},
+		{"superseded release kept", []string{"go1.22.5", "go1.22.6"}, []string{"go1.23.2", "go1.22.6"}, 0},
+		{"outdated", []string{"go1.22.5", "go1.23.2"}, []string{"go1.23.2", "go1.22.6"}, outdatedExitOutdated},
+		{"release index unavailable", []string{"go1.22.6"}, nil, outdatedExitError},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupOutdated(t, tt.installed, tt.releases)
+			if got := runOutdated(io.Discard, io.Discard); got != tt.want {
+				t.Errorf("runOutdated() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRunOutdatedWithoutVersionsDir(t *testing.T) {
+	setupOutdated(t, nil, []string{"go1.22.6"})
+	if got := runOutdated(io.Discard, io.Discard); got != outdatedExitError {
+		t.Errorf("runOutdated() = %d, want %d", got, outdatedExitError)
+	}
+}
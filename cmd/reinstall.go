@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/installer"
+
+	"github.com/spf13/cobra"
+)
+
+var reinstallGoProxy bool
+
+var reinstallCmd = &cobra.Command{
+	Use:   "reinstall <version>",
+	Short: "Download a Go version again and replace its installation",
+	Long: `Download an installed Go version again and replace its installation, repairing
+edited, missing or extra files (see 'sgv verify').
+
+The new copy is unpacked next to the old one and swapped in atomically, so this
+works for the active version too and never leaves it half replaced. A verified
+archive in the download cache is reused instead of downloading it again. Env files
+are kept.
+
+Examples:
+  sgv reinstall 1.22.1
+  sgv reinstall --goproxy 1.22.1`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		versionStr, err := expandVersionArg(args[0], runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if !strings.HasPrefix(versionStr, "go") {
+			versionStr = "go" + versionStr
+		}
+
+		if _, err := os.Stat(filepath.Join(config.VersionsDir, versionStr)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: Go version %s is not installed. Use 'sgv install %s' instead.\n", versionStr, args[0])
+			os.Exit(1)
+		}
+
+		if err := installer.Reinstall(versionStr, installer.Options{GoProxy: reinstallGoProxy}); err != nil {
+			fmt.Fprintf(os.Stderr, "Error reinstalling Go version %s: %v\n", versionStr, err)
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully reinstalled Go version %s.\n", versionStr)
+	},
+}
+
+func init() {
+	reinstallCmd.Flags().BoolVar(&reinstallGoProxy, "goproxy", false, "Download the golang.org/toolchain module from the Go module proxy")
+	rootCmd.AddCommand(reinstallCmd)
+}
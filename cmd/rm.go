@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 
 	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
 	"github.com/fun7257/sgv/internal/version"
 	"github.com/samber/lo"
 
@@ -19,7 +21,9 @@ var rmCmd = &cobra.Command{
 	Short: "Uninstall one or more Go versions",
 	Long: `Uninstall one or more previously installed Go versions from your system.
 
-You can specify multiple full version numbers (e.g., 1.22.1) or major versions (e.g., 1.22) to remove all its sub-versions.`,
+You can specify multiple full version numbers (e.g., 1.22.1), major versions (e.g., 1.22,
+or 1 for every Go 1 release) to remove all its sub-versions, or version constraints
+(e.g., '<1.21' or '~1.21.3') to remove every installed version they match.`,
 	Args: cobra.MinimumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
 		currentVersion, err := version.GetCurrentVersion()
@@ -37,7 +41,7 @@ You can specify multiple full version numbers (e.g., 1.22.1) or major versions (
 			return v, struct{}{}
 		})
 
-		versionsToUninstall := findVersionsToUninstall(args, installedVersions)
+		versionsToUninstall := findMatchingVersions(args, installedVersions)
 
 		// Filter out non-existent versions and the active version
 		var finalVersionsToUninstall []string
@@ -70,11 +74,24 @@ You can specify multiple full version numbers (e.g., 1.22.1) or major versions (
 			return
 		}
 
+		// Keep other sgv processes from switching to a version while it is removed
+		globalLock, err := lock.Global()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer globalLock.Release()
+
+		// The active version may have changed while waiting for confirmation
+		currentVersion, _ = version.GetCurrentVersion()
+
 		// Delete the version directories
 		for _, v := range finalVersionsToUninstall {
-			fmt.Printf("Uninstalling Go version %s...\n", v)
-			versionPath := filepath.Join(config.VersionsDir, v)
-			if err := os.RemoveAll(versionPath); err != nil {
+			if v == currentVersion {
+				fmt.Fprintf(os.Stderr, "Info: Cannot uninstall currently active Go version (%s). It will be skipped.\n", v)
+				continue
+			}
+			if err := removeVersion(v); err != nil {
 				fmt.Fprintf(os.Stderr, "Error uninstalling Go version %s: %v\n", v, err)
 				// Continue to the next version instead of exiting
 			} else {
@@ -84,39 +101,51 @@ You can specify multiple full version numbers (e.g., 1.22.1) or major versions (
 	},
 }
 
-// findVersionsToUninstall resolves user input (like "1.22") into a list of full version strings.
-func findVersionsToUninstall(args []string, installedVersions []string) []string {
+// removeVersion deletes the installation of v while holding its install lock.
+func removeVersion(v string) error {
+	fmt.Printf("Uninstalling Go version %s...\n", v)
+
+	l, err := lock.Version(v)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	return os.RemoveAll(filepath.Join(config.VersionsDir, v))
+}
+
+// findMatchingVersions resolves user input into a list of full version strings. Aliases
+// and symbolic versions are expanded, exact versions are taken as is, while constraints
+// (like "1.22" or "<1.21") expand to every installed version they match.
+func findMatchingVersions(args []string, installedVersions []string) []string {
 	var versionsToUninstall []string
 	for _, arg := range args {
-		normalizedArg := arg
-		if !strings.HasPrefix(normalizedArg, "go") {
-			normalizedArg = "go" + normalizedArg
+		if expanded, err := expandVersionArg(arg, runtime.GOOS, runtime.GOARCH); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+		} else {
+			arg = expanded
 		}
 
-		// Check if it's a major version (e.g., "go1.22")
-		isMajorVersion := !strings.Contains(strings.TrimPrefix(normalizedArg, "go"), ".")
-		if !isMajorVersion {
-			parts := strings.Split(strings.TrimPrefix(normalizedArg, "go"), ".")
-			if len(parts) == 2 {
-				isMajorVersion = true
-			}
+		c, err := version.ParseConstraint(arg)
+		if version.IsDevel(arg) || err != nil {
+			// Not a version: report it as not installed below
+			versionsToUninstall = append(versionsToUninstall, arg)
+			continue
+		}
+		if exact, ok := c.Exact(); ok {
+			versionsToUninstall = append(versionsToUninstall, exact)
+			continue
 		}
 
-		if isMajorVersion {
-			// It's a major version, find all installed sub-versions
-			found := false
-			for _, installed := range installedVersions {
-				if strings.HasPrefix(installed, normalizedArg+".") || installed == normalizedArg {
-					versionsToUninstall = append(versionsToUninstall, installed)
-					found = true
-				}
-			}
-			if !found {
-				fmt.Fprintf(os.Stderr, "Info: No installed versions found for major version %s.\n", arg)
+		found := false
+		for _, installed := range installedVersions {
+			if c.Match(installed) {
+				versionsToUninstall = append(versionsToUninstall, installed)
+				found = true
 			}
-		} else {
-			// It's a full version string
-			versionsToUninstall = append(versionsToUninstall, normalizedArg)
+		}
+		if !found {
+			fmt.Fprintf(os.Stderr, "Info: No installed versions found matching %s.\n", arg)
 		}
 	}
 	return versionsToUninstall
@@ -17,6 +17,7 @@ import (
 
 var (
 	noSwitch bool
+	remote   bool
 )
 
 var rootCmd = &cobra.Command{
@@ -25,14 +26,33 @@ var rootCmd = &cobra.Command{
 	Long: `A fast and flexible Go Version manager built with love by Howell.
 
 This tool allows you to easily install and switch between different Go versions.
-You can also install a version without switching to it by using the --no-switch flag.`,
+You can also install a version without switching to it by using the --no-switch flag.
+
+Instead of an exact version you can give a constraint, which resolves to the newest
+matching installed version (or, with --remote or if none is installed, the newest
+matching release):
+
+  sgv 1.22            # Newest 1.22.x release
+  sgv '~1.21.3'       # >=1.21.3 <1.22.0
+  sgv '^1.21'         # >=1.21.0 <2.0.0
+  sgv '>=1.21 <1.23'  # Comparisons may be combined, and alternatives joined with ||
+
+Keywords and aliases (see 'sgv alias') are accepted too:
+
+  sgv stable          # Newest stable release
+  sgv oldstable       # Newest release of the previous minor version
+  sgv 1.22.latest     # Newest 1.22.x release in the remote index
+  sgv prod            # The version the alias "prod" stands for`,
 	Args: cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		versionStr := args[0]
-
-		// Normalize version string (e.g., "1.22.1" -> "go1.22.1")
-		if !strings.HasPrefix(versionStr, "go") {
-			versionStr = "go" + versionStr
+		// Resolve the version or constraint (e.g., "1.22.1", "1.22" or "~1.21") to a version name
+		versionStr, err := resolveVersionArg(args[0], remote)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		if strings.TrimPrefix(versionStr, "go") != strings.TrimPrefix(args[0], "go") {
+			fmt.Printf("Resolved %s to Go version %s\n", args[0], versionStr)
 		}
 
 		// Check if the requested version is supported
@@ -115,6 +135,7 @@ func init() {
 	checkPlatformSupport()
 	cobra.OnInitialize(config.Init)
 	rootCmd.Flags().BoolVar(&noSwitch, "no-switch", false, "Install a Go version without switching to it")
+	rootCmd.Flags().BoolVar(&remote, "remote", false, "Resolve version constraints against the newest remote releases instead of installed versions")
 }
 
 // checkPlatformSupport ensures the current platform is supported
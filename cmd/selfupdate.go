@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/selfupdate"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	selfUpdateCheck bool
+	selfUpdateForce bool
+)
+
+var selfUpdateCmd = &cobra.Command{
+	Use:   "self-update",
+	Short: "Update sgv itself to the latest release",
+	Long: `Update sgv itself to the latest release.
+
+The latest release is read from the release index (SGV_UPDATE_URL, by default the
+GitHub releases of sgv). If it is newer than the running sgv, the archive for this
+platform is downloaded, verified against its published SHA-256 checksum and the
+running binary is replaced atomically. Your shell configuration is not touched.
+
+Examples:
+  sgv self-update          # Update to the latest release
+  sgv self-update --check  # Only report whether an update is available`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		current := version.GetSGVReleaseVersion()
+
+		release, err := selfupdate.Latest(config.UpdateURL)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error checking for updates: %v\n", err)
+			os.Exit(1)
+		}
+
+		if !release.Newer(current) && (selfUpdateCheck || !selfUpdateForce) {
+			fmt.Printf("sgv %s is up to date (latest release: %s).\n", current, release.Version)
+			return
+		}
+		if selfUpdateCheck {
+			fmt.Printf("sgv %s is available (current: %s). Run 'sgv self-update' to update.\n", color.GreenString(release.Version), current)
+			return
+		}
+
+		asset, err := release.Archive(runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		checksum, err := release.Checksum(asset)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+
+		exe, err := os.Executable()
+		if err == nil {
+			exe, err = filepath.EvalSymlinks(exe)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error locating the sgv binary: %v\n", err)
+			os.Exit(1)
+		}
+
+		fmt.Printf("Updating sgv %s to %s...\n", current, release.Version)
+		if err := selfupdate.Apply(asset, checksum, exe); err != nil {
+			fmt.Fprintf(os.Stderr, "Error updating sgv: %v\n", err)
+			if errors.Is(err, os.ErrPermission) {
+				fmt.Fprintf(os.Stderr, "%s is not writable; run 'sudo sgv self-update' instead.\n", filepath.Dir(exe))
+			}
+			os.Exit(1)
+		}
+		fmt.Printf("Successfully updated %s to sgv %s.\n", exe, release.Version)
+	},
+}
+
+func init() {
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateCheck, "check", false, "Only report whether a newer release is available")
+	selfUpdateCmd.Flags().BoolVar(&selfUpdateForce, "force", false, "Reinstall the latest release even if sgv is up to date")
+	rootCmd.AddCommand(selfUpdateCmd)
+}
@@ -9,27 +9,50 @@ import (
 	"github.com/fun7257/sgv/internal/version"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
-	"golang.org/x/mod/semver"
 )
 
-var interactive bool
+var (
+	interactive bool
+	subUnstable bool
+)
 
 // subCmd represents the sub command
 var subCmd = &cobra.Command{
-	Use:   "sub [major_version]",
+	Use:   "sub [major_version | constraint]",
 	Short: "List minor versions for a specific Go major version",
-	Long: `List all available minor patch versions for a given Go major version.
+	Long: `List all available minor patch versions for a given Go major version, or all
+available versions matching a version constraint (see 'sgv --help').
   Example: sgv sub 1.22
-  Use -i or --interactive flag to interactively select and install a version using arrow keys.`,
+  Example: sgv sub '>=1.21 <1.23'
+  Example: sgv sub stable
+  Use -i or --interactive flag to interactively select and install a version using arrow keys.
+  Use --unstable to also list prereleases (release candidates and betas).`,
 	Args: cobra.ExactArgs(1),
 	RunE: func(cmd *cobra.Command, args []string) error {
-		majorVersion := strings.TrimPrefix(args[0], "go")
-		if !strings.HasPrefix(majorVersion, "1.") {
-			majorVersion = "1." + majorVersion
+		arg, err := expandVersionArg(args[0], runtime.GOOS, runtime.GOARCH)
+		if err != nil {
+			return err
+		}
+		if version.IsKeyword(args[0]) {
+			// "sgv sub stable" lists every release of the stable minor version
+			arg = version.MajorVersion(arg)
+		}
+
+		// A bare minor number such as "22" stands for "1.22"
+		arg = strings.TrimPrefix(arg, "go")
+		if !strings.ContainsAny(arg, ".<>=~^*x|") {
+			arg = "1." + arg
+		}
+		constraint, err := version.ParseConstraint(arg)
+		if err != nil {
+			return err
+		}
+		if subUnstable {
+			constraint = constraint.WithPrereleases()
 		}
 
 		// Check if the major version is at least 1.13
-		if semver.Compare("v"+majorVersion, "v1.13") < 0 {
+		if version.MajorVersion(arg) == arg && version.Compare("go"+arg, "go1.13") < 0 {
 			return fmt.Errorf("this command is only available for Go versions 1.13 and higher")
 		}
 
@@ -37,7 +60,12 @@ var subCmd = &cobra.Command{
 		currentOS := runtime.GOOS
 		currentArch := runtime.GOARCH
 
-		allVersions, err := version.GetStableGoVersions()
+		var allVersions []version.GoVersion
+		if subUnstable {
+			allVersions, err = version.GetRemoteVersions()
+		} else {
+			allVersions, err = version.GetStableGoVersions()
+		}
 		if err != nil {
 			return fmt.Errorf("failed to fetch Go versions: %w", err)
 		}
@@ -54,7 +82,7 @@ var subCmd = &cobra.Command{
 
 		var matchedVersions []version.GoVersion
 		for _, v := range allVersions {
-			if strings.HasPrefix(v.Version, "go"+majorVersion) {
+			if constraint.Match(v.Version) && isGoVersionSupported(v.Version) {
 				matchedVersions = append(matchedVersions, v)
 			}
 		}
@@ -71,10 +99,14 @@ var subCmd = &cobra.Command{
 			sortedVersions = append(sortedVersions, versionStr)
 		}
 		sort.Slice(sortedVersions, func(i, j int) bool {
-			return semver.Compare("v"+strings.TrimPrefix(sortedVersions[i], "go"), "v"+strings.TrimPrefix(sortedVersions[j], "go")) < 0
+			return version.Compare(sortedVersions[i], sortedVersions[j]) < 0
 		})
 
-		fmt.Printf("Available minor versions for go%s:\n", majorVersion)
+		if version.MajorVersion(arg) == arg {
+			fmt.Printf("Available minor versions for go%s:\n", arg)
+		} else {
+			fmt.Printf("Available versions matching %s:\n", arg)
+		}
 		if len(sortedVersions) == 0 {
 			fmt.Println("No versions found for the specified major version.")
 			return nil
@@ -248,5 +280,6 @@ func handleInteractiveSelection(allVersions []string, installableVersions []stri
 
 func init() {
 	subCmd.Flags().BoolVarP(&interactive, "interactive", "i", false, "Interactive mode to select and install a version using arrow keys")
+	subCmd.Flags().BoolVar(&subUnstable, "unstable", false, "Include prereleases (release candidates and betas)")
 	rootCmd.AddCommand(subCmd)
 }
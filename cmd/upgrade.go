@@ -0,0 +1,191 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"slices"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/env"
+	"github.com/fun7257/sgv/internal/installer"
+	"github.com/fun7257/sgv/internal/lock"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+var (
+	upgradeAll   bool
+	upgradePrune bool
+)
+
+var upgradeCmd = &cobra.Command{
+	Use:   "upgrade [major_version]",
+	Short: "Upgrade installed minor versions to their latest patch release",
+	Long: `Upgrade installed Go minor versions to their newest stable patch release.
+
+For each minor version upgraded, the newest patch release is installed, the
+environment variables of the older patch releases are copied to it, and sgv switches
+to it if one of the older releases was active. With --prune the superseded patch
+releases are removed afterwards, and their environment variables are moved instead.
+Variables the newest release already sets keep their value; differing values are
+reported.
+
+Without an argument the minor version of the active Go version is upgraded.
+
+Examples:
+  sgv upgrade                # Upgrade the active minor version
+  sgv upgrade 1.22           # Upgrade Go 1.22 to its latest patch
+  sgv upgrade --all          # Upgrade every installed minor version
+  sgv upgrade --all --prune  # ...and remove the superseded patch releases`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if upgradeAll && len(args) > 0 {
+			fmt.Fprintln(os.Stderr, "Error: give either a major version or --all, not both.")
+			os.Exit(1)
+		}
+
+		currentVersion, _ := version.GetCurrentVersion()
+
+		var minor string
+		switch {
+		case upgradeAll:
+		case len(args) > 0:
+			expanded, err := expandVersionArg(args[0], runtime.GOOS, runtime.GOARCH)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+			minor = version.MajorVersion(expanded)
+			if minor == "" {
+				fmt.Fprintf(os.Stderr, "Error: invalid major version %q.\n", args[0])
+				os.Exit(1)
+			}
+		case currentVersion != "" && !version.IsDevel(currentVersion):
+			minor = version.MajorVersion(currentVersion)
+		default:
+			fmt.Fprintln(os.Stderr, "Error: no active Go release. Give a major version (e.g. 'sgv upgrade 1.22') or use --all.")
+			os.Exit(1)
+		}
+
+		installedVersions, err := version.GetLocalVersions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: could not list installed Go versions: %v\n", err)
+			os.Exit(1)
+		}
+		remoteVersions, err := version.GetStableGoVersions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error fetching Go versions: %v\n", err)
+			os.Exit(1)
+		}
+
+		lines := version.InstalledLines(installedVersions, remoteVersions, runtime.GOOS, runtime.GOARCH)
+		if minor != "" {
+			i := slices.IndexFunc(lines, func(l version.Line) bool { return l.Minor == minor })
+			if i < 0 {
+				fmt.Fprintf(os.Stderr, "Error: no Go %s release is installed. Use 'sgv install %s' instead.\n", minor, minor)
+				os.Exit(1)
+			}
+			lines = lines[i : i+1]
+		}
+
+		failed := 0
+		for _, line := range lines {
+			if !line.Outdated() {
+				if line.Latest == "" {
+					fmt.Printf("Go %s: no stable release found for %s/%s, skipping.\n", line.Minor, runtime.GOOS, runtime.GOARCH)
+				} else {
+					fmt.Printf("Go %s: %s is up to date.\n", line.Minor, line.Newest())
+				}
+				// Releases kept by an earlier upgrade are still cleaned up with --prune
+				if !upgradePrune || len(line.Superseded()) == 0 {
+					continue
+				}
+			}
+			if err := upgradeLine(line, currentVersion); err != nil {
+				fmt.Fprintf(os.Stderr, "Error upgrading Go %s: %v\n", line.Minor, err)
+				failed++
+			}
+		}
+
+		if failed > 0 {
+			os.Exit(1)
+		}
+	},
+}
+
+// upgradeLine installs the latest patch release of line, moves the env files of the
+// installed releases to it, switches to it if currentVersion belongs to the line and,
+// with --prune, removes the superseded releases.
+func upgradeLine(line version.Line, currentVersion string) error {
+	if line.Outdated() {
+		fmt.Printf("Go %s: upgrading %s to %s\n", line.Minor, line.Newest(), color.GreenString(line.Latest))
+		if err := installer.Install(line.Latest); err != nil {
+			return err
+		}
+	}
+
+	// Carry over the newest release's variables first, so they win over older ones.
+	// Releases that stay installed keep their env file, so it is only moved with --prune
+	superseded := line.Superseded()
+	for _, v := range superseded {
+		migrate, verb := env.CopyEnvVars, "Copied"
+		if upgradePrune {
+			migrate, verb = env.MoveEnvVars, "Moved"
+		}
+		m, err := migrate(v, line.Latest)
+		if err != nil {
+			return err
+		}
+		if m.Added > 0 {
+			fmt.Printf("%s %d environment variable(s) from %s to %s.\n", verb, m.Added, v, line.Latest)
+		}
+		for _, key := range m.Conflicts {
+			fmt.Fprintf(os.Stderr, "Warning: %s is set differently for %s and %s; keeping the value of %s.\n", key, v, line.Latest, line.Latest)
+		}
+	}
+
+	if slices.Contains(superseded, currentVersion) {
+		if err := version.SwitchToVersion(line.Latest); err != nil {
+			return fmt.Errorf("failed to switch to %s: %w", line.Latest, err)
+		}
+		fmt.Printf("Successfully switched to Go version %s\n", line.Latest)
+	}
+
+	if upgradePrune {
+		return pruneVersions(superseded)
+	}
+	fmt.Printf("Kept %s; remove with 'sgv rm %s' or upgrade with --prune.\n", strings.Join(superseded, ", "), strings.Join(superseded, " "))
+	return nil
+}
+
+// pruneVersions removes the given versions, skipping the active one.
+func pruneVersions(versions []string) error {
+	// Keep other sgv processes from switching to a version while it is removed
+	globalLock, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer globalLock.Release()
+
+	currentVersion, _ := version.GetCurrentVersion()
+	for _, v := range versions {
+		if v == currentVersion {
+			fmt.Fprintf(os.Stderr, "Info: Cannot uninstall currently active Go version (%s). It will be skipped.\n", v)
+			continue
+		}
+		if err := removeVersion(v); err != nil {
+			return fmt.Errorf("failed to uninstall %s: %w", v, err)
+		}
+		fmt.Printf("Successfully uninstalled Go version %s.\n", v)
+	}
+	return nil
+}
+
+func init() {
+	upgradeCmd.Flags().BoolVar(&upgradeAll, "all", false, "Upgrade every installed minor version")
+	upgradeCmd.Flags().BoolVar(&upgradePrune, "prune", false, "Remove the superseded patch releases after upgrading")
+	rootCmd.AddCommand(upgradeCmd)
+}
@@ -5,9 +5,13 @@ import (
 	"os"
 	"path/filepath"
 	"regexp"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
-	"golang.org/x/mod/semver"
+	"github.com/fun7257/sgv/internal/alias"
+	"github.com/fun7257/sgv/internal/version"
 )
 
 // findGoModVersion searches for a go.mod file in the current directory
@@ -25,8 +29,8 @@ func findGoModVersion() (string, error) {
 			return "", fmt.Errorf("failed to read go.mod file: %w", err)
 		}
 
-		goRe := regexp.MustCompile(`^go\s+(\d+\.\d+(?:\.\d+)?)`)
-		toolchainRe := regexp.MustCompile(`^toolchain\s+go(\d+\.\d+(?:\.\d+)?)`)
+		goRe := regexp.MustCompile(`^go\s+(\d+\.\d+(?:\.\d+)?(?:(?:rc|beta)\d+)?)`)
+		toolchainRe := regexp.MustCompile(`^toolchain\s+go(\d+\.\d+(?:\.\d+)?(?:(?:rc|beta)\d+)?)`)
 
 		var goVersion, toolchainVersion string
 
@@ -40,7 +44,7 @@ func findGoModVersion() (string, error) {
 			}
 		}
 
-		if toolchainVersion != "" && (goVersion == "" || semver.Compare(normalizeGoVersion(toolchainVersion), normalizeGoVersion(goVersion)) > 0) {
+		if toolchainVersion != "" && (goVersion == "" || version.Compare(toolchainVersion, goVersion) > 0) {
 			return normalizeGoModVersion(toolchainVersion), nil
 		}
 
@@ -60,48 +64,117 @@ func findGoModVersion() (string, error) {
 // normalizeGoModVersion adjusts the Go version string based on the go.mod parsing rules.
 // For Go 1.21 and later, "go1.21" is treated as "go1.21.0".
 // For versions below 1.21, "go1.20" is treated as "go1.20".
+// Prereleases such as "go1.23rc1" are left unchanged.
 func normalizeGoModVersion(v string) string {
 	// Remove "go" prefix for easier parsing
 	versionNum := strings.TrimPrefix(v, "go")
 
-	// Check if it's a major.minor version (e.g., "1.21")
-	if !strings.Contains(versionNum, ".") {
-		return v // Should not happen for valid Go versions
+	// Check if it's a plain major.minor version (e.g., "1.21")
+	if !version.IsValid(v) || version.IsPrerelease(v) || strings.Count(versionNum, ".") != 1 {
+		return v
 	}
 
-	parts := strings.Split(versionNum, ".")
-	if len(parts) == 2 && semver.Compare("v"+versionNum, "v1.21") >= 0 { // Major.Minor version
+	if version.Compare(v, "go1.21") >= 0 {
 		return "go" + versionNum + ".0"
 	}
 	return v
 }
 
-// normalizeGoVersion ensures the version string starts with 'v' for semver comparison.
-func normalizeGoVersion(v string) string {
-	if !strings.HasPrefix(v, "go") {
-		v = "go" + v
+// expandVersionArg replaces a user-defined alias with the version it stands for,
+// and resolves symbolic versions ("stable", "oldstable" or "1.22.latest") to the
+// release they currently name for goos/goarch. Other arguments are returned as is.
+func expandVersionArg(arg, goos, goarch string) (string, error) {
+	target, ok, err := alias.Get(arg)
+	if err != nil {
+		return "", err
 	}
-	if !strings.HasPrefix(v, "v") {
-		return "v" + strings.TrimPrefix(v, "go")
+	if !ok {
+		target = arg
 	}
-	return v
+
+	if version.IsKeyword(target) {
+		return version.ResolveKeyword(target, goos, goarch)
+	}
+	return target, nil
+}
+
+// resolveVersionArg turns a version or version constraint given on the command line
+// into a version name. Aliases and symbolic versions are expanded first (see
+// expandVersionArg). Exact versions and toolchains built from source are used as
+// is. For other constraints (e.g., "1.22" or ">=1.21 <1.23") the newest matching
+// installed version is picked, unless remote is set or none is installed, in which
+// case the newest matching release for this platform is.
+func resolveVersionArg(arg string, remote bool) (string, error) {
+	arg, err := expandVersionArg(arg, runtime.GOOS, runtime.GOARCH)
+	if err != nil {
+		return "", err
+	}
+	if version.IsDevel(arg) {
+		return arg, nil
+	}
+
+	c, err := version.ParseConstraint(arg)
+	if err != nil {
+		return "", err
+	}
+	if exact, ok := c.Exact(); ok {
+		return exact, nil
+	}
+
+	if !remote {
+		installed, err := version.ResolveInstalled(c)
+		if err != nil {
+			return "", err
+		}
+		if installed != "" {
+			return installed, nil
+		}
+	}
+	return version.ResolveRemote(c, runtime.GOOS, runtime.GOARCH)
 }
 
 // isGoVersionCompatible checks if candidateVersion is greater than or equal to requiredVersion.
+// Toolchains built from source (gotip-*) are considered newer than any release.
 func isGoVersionCompatible(candidateVersion, requiredVersion string) bool {
-	candidate := normalizeGoVersion(candidateVersion)
-	required := normalizeGoVersion(requiredVersion)
-	return semver.Compare(candidate, required) >= 0
+	return version.IsDevel(candidateVersion) || version.Compare(candidateVersion, requiredVersion) >= 0
 }
 
-// isGoVersionSupported checks if the given Go version is 1.13 or later.
+// isGoVersionSupported checks if the given Go version is 1.13 or later, including
+// prereleases of later versions such as go1.24rc2, or a toolchain built from source.
 func isGoVersionSupported(v string) bool {
-	// Normalize to semver format (e.g., "go1.13" -> "v1.13")
-	normalizedVersion := normalizeGoVersion(v)
+	return version.IsDevel(v) || version.Compare(v, "go1.13") >= 0
+}
 
-	// Define the minimum supported version
-	minSupportedVersion := normalizeGoVersion("go1.13")
+// formatSize renders a byte count in human readable form (e.g., "68.4 MiB").
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// parseAge parses a duration such as "72h", "30d" or "2w".
+// In addition to the units understood by time.ParseDuration, "d" (days) and "w" (weeks) are accepted.
+func parseAge(s string) (time.Duration, error) {
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
 
-	// Compare using semver.Compare
-	return semver.Compare(normalizedVersion, minSupportedVersion) >= 0
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q", s)
+	}
+	return d, nil
 }
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/manifest"
+	"github.com/fun7257/sgv/internal/version"
+
+	"github.com/fatih/color"
+	"github.com/spf13/cobra"
+)
+
+// maxProblemsShown limits how many differences are listed per damaged version.
+const maxProblemsShown = 10
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify [version...]",
+	Short: "Check installed Go versions for modified, missing or extra files",
+	Long: `Check installed Go versions against the manifest (file list and SHA-256 hashes)
+recorded when they were installed. Without arguments all installed versions are
+checked; major versions (e.g., 1.22) select all their installed releases.
+
+The command exits with a non-zero status if any version is damaged. Use
+'sgv reinstall <version>' to repair it.
+
+Examples:
+  sgv verify
+  sgv verify 1.22.1`,
+	Run: func(cmd *cobra.Command, args []string) {
+		installedVersions, err := version.GetLocalVersions()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: could not list installed Go versions: %v\n", err)
+			os.Exit(1)
+		}
+
+		versions := installedVersions
+		if len(args) > 0 {
+			versions = nil
+			for _, v := range findMatchingVersions(args, installedVersions) {
+				if _, err := os.Stat(filepath.Join(config.VersionsDir, v)); err != nil {
+					fmt.Fprintf(os.Stderr, "Info: Go version %s is not installed. Skipping.\n", v)
+					continue
+				}
+				versions = append(versions, v)
+			}
+		}
+		if len(versions) == 0 {
+			fmt.Println("No versions to verify.")
+			return
+		}
+
+		var damaged []string
+		for _, v := range versions {
+			m, err := manifest.Load(manifest.Path(v))
+			if os.IsNotExist(err) {
+				fmt.Printf("%s: %s, it was installed before sgv recorded manifests. Run 'sgv reinstall %s' to record one.\n",
+					v, color.YellowString("no manifest"), strings.TrimPrefix(v, "go"))
+				continue
+			}
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying %s: %v\n", v, err)
+				damaged = append(damaged, v)
+				continue
+			}
+
+			problems, err := m.Verify(filepath.Join(config.VersionsDir, v, "go"))
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error verifying %s: %v\n", v, err)
+				damaged = append(damaged, v)
+				continue
+			}
+			if len(problems) == 0 {
+				fmt.Printf("%s: %s (%d files)\n", v, color.GreenString("ok"), len(m.Entries))
+				continue
+			}
+
+			damaged = append(damaged, v)
+			fmt.Printf("%s: %s, %d problem(s)\n", v, color.RedString("damaged"), len(problems))
+			for i, p := range problems {
+				if i == maxProblemsShown {
+					fmt.Printf("  ... and %d more\n", len(problems)-maxProblemsShown)
+					break
+				}
+				fmt.Printf("  %s\n", p)
+			}
+		}
+
+		if len(damaged) > 0 {
+			fmt.Fprintf(os.Stderr, "Error: %d version(s) failed verification. Repair them with 'sgv reinstall <version>'.\n", len(damaged))
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(verifyCmd)
+}
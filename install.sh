@@ -57,12 +57,14 @@ update_sgv_config() {
     echo "" >> "$config_file"
     echo "# SGV wrapper function for seamless environment variable loading" >> "$config_file"
     echo "sgv() {" >> "$config_file"
+    echo "    local previous_version=\"\$(readlink \"\$HOME/.sgv/current\" 2>/dev/null)\"" >> "$config_file"
     echo "    command sgv \"\$@\"" >> "$config_file"
     echo "    local exit_code=\$?" >> "$config_file"
     echo "    # Auto-load environment variables after successful operations" >> "$config_file"
     echo "    if [ \$exit_code -eq 0 ]; then" >> "$config_file"
-    echo "        # Check for version switch (direct version argument)" >> "$config_file"
-    echo "        if [ \$# -eq 1 ] && [[ \"\$1\" =~ ^(go)?[0-9]+\\.[0-9]+(\\.[0-9]+)?\$ ]]; then" >> "$config_file"
+    echo "        # Check for version switch (version, constraint, keyword or alias argument)" >> "$config_file"
+    echo "        local version_re='^(go)?([0-9~^<>=]|tip-)'" >> "$config_file"
+    echo "        if [[ \"\$1\" =~ \$version_re ]] || [ \"\$(readlink \"\$HOME/.sgv/current\" 2>/dev/null)\" != \"\$previous_version\" ]; then" >> "$config_file"
     echo "            eval \"\$(command sgv env --shell --clean 2>/dev/null || true)\"" >> "$config_file"
     echo "        # Check for env command with write or unset flags" >> "$config_file"
     echo "        elif [ \"\$1\" = \"env\" ] && { [ \"\$2\" = \"-w\" ] || [ \"\$2\" = \"--write\" ] || [ \"\$2\" = \"-u\" ] || [ \"\$2\" = \"--unset\" ]; }; then" >> "$config_file"
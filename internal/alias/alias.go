@@ -0,0 +1,135 @@
+// Package alias stores user-defined names for Go versions (e.g., "prod" for
+// "1.22.6") in config.AliasesFile. An alias may stand for an exact version, a
+// version constraint, a symbolic version such as "stable" or a toolchain built
+// from source.
+package alias
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// nameRe matches valid alias names.
+var nameRe = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+
+// Load returns all aliases by name. A missing aliases file yields no aliases.
+func Load() (map[string]string, error) {
+	aliases := make(map[string]string)
+
+	data, err := os.ReadFile(config.AliasesFile)
+	if os.IsNotExist(err) {
+		return aliases, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read aliases: %w", err)
+	}
+	if err := json.Unmarshal(data, &aliases); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", config.AliasesFile, err)
+	}
+	return aliases, nil
+}
+
+// Get returns the version the alias name stands for, and false if there is no
+// such alias.
+func Get(name string) (string, bool, error) {
+	aliases, err := Load()
+	if err != nil {
+		return "", false, err
+	}
+	target, ok := aliases[name]
+	return target, ok, nil
+}
+
+// ValidateName checks that name can be used as an alias: it must start with a
+// letter, and must not be read as a version, a constraint or a symbolic version.
+func ValidateName(name string) error {
+	if !nameRe.MatchString(name) {
+		return fmt.Errorf("invalid alias name %q: use letters, digits, '-' and '_', starting with a letter", name)
+	}
+	if _, err := version.ParseConstraint(name); err == nil || version.IsKeyword(name) || name == "tip" {
+		return fmt.Errorf("invalid alias name %q: it already names a Go version", name)
+	}
+	return nil
+}
+
+// ValidateTarget checks that target is something an alias can stand for: an exact
+// version, a constraint, a symbolic version or a toolchain built from source.
+func ValidateTarget(target string) error {
+	if version.IsDevel(target) || version.IsKeyword(target) {
+		return nil
+	}
+	if _, err := version.ParseConstraint(target); err != nil {
+		return fmt.Errorf("invalid alias target: %w", err)
+	}
+	return nil
+}
+
+// Set makes name an alias for target, replacing any previous alias of that name.
+func Set(name, target string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	if err := ValidateTarget(target); err != nil {
+		return err
+	}
+
+	return update(func(aliases map[string]string) error {
+		aliases[name] = target
+		return nil
+	})
+}
+
+// Remove deletes the alias name.
+func Remove(name string) error {
+	return update(func(aliases map[string]string) error {
+		if _, ok := aliases[name]; !ok {
+			return fmt.Errorf("alias %q does not exist", name)
+		}
+		delete(aliases, name)
+		return nil
+	})
+}
+
+// update applies fn to the aliases and writes them back, holding the global lock
+// so that concurrent sgv processes don't lose each other's changes.
+func update(fn func(aliases map[string]string) error) error {
+	l, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	aliases, err := Load()
+	if err != nil {
+		return err
+	}
+	if err := fn(aliases); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(aliases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode aliases: %w", err)
+	}
+
+	// Write a temporary file and rename it, so readers never see a partial file
+	if err := os.MkdirAll(filepath.Dir(config.AliasesFile), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(config.AliasesFile), err)
+	}
+	tmp := config.AliasesFile + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write aliases: %w", err)
+	}
+	if err := os.Rename(tmp, config.AliasesFile); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("failed to write aliases: %w", err)
+	}
+	return nil
+}
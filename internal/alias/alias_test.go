@@ -0,0 +1,77 @@
+package alias
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+func setupAliases(t *testing.T) {
+	t.Helper()
+	tmp := t.TempDir()
+	originalFile, originalLocks, originalTimeout := config.AliasesFile, config.LocksDir, config.LockTimeout
+	config.AliasesFile = filepath.Join(tmp, "aliases.json")
+	config.LocksDir = filepath.Join(tmp, "locks")
+	config.LockTimeout = time.Minute
+	t.Cleanup(func() {
+		config.AliasesFile, config.LocksDir, config.LockTimeout = originalFile, originalLocks, originalTimeout
+	})
+}
+
+func TestSetGetRemove(t *testing.T) {
+	setupAliases(t)
+
+	if aliases, err := Load(); err != nil || len(aliases) != 0 {
+		t.Fatalf("Load without aliases file = %v, %v; want no aliases", aliases, err)
+	}
+
+	for name, target := range map[string]string{"prod": "1.22.6", "legacy": "~1.20", "edge": "stable", "dev": "gotip-1a2b3c4d5e"} {
+		if err := Set(name, target); err != nil {
+			t.Fatalf("Set(%q, %q) failed: %v", name, target, err)
+		}
+	}
+	if err := Set("prod", "1.22.7"); err != nil {
+		t.Fatalf("overwriting an alias failed: %v", err)
+	}
+
+	if target, ok, err := Get("prod"); err != nil || !ok || target != "1.22.7" {
+		t.Errorf("Get(prod) = %q, %v, %v; want 1.22.7", target, ok, err)
+	}
+	if _, ok, err := Get("staging"); err != nil || ok {
+		t.Errorf("Get(staging) = %v, %v; want no alias", ok, err)
+	}
+
+	if err := Remove("legacy"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	if err := Remove("legacy"); err == nil {
+		t.Error("expected removing a missing alias to fail")
+	}
+
+	aliases, err := Load()
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	want := map[string]string{"prod": "1.22.7", "edge": "stable", "dev": "gotip-1a2b3c4d5e"}
+	if !reflect.DeepEqual(aliases, want) {
+		t.Errorf("Load = %v, want %v", aliases, want)
+	}
+}
+
+func TestSetInvalid(t *testing.T) {
+	setupAliases(t)
+
+	for _, name := range []string{"", "1prod", "pr od", "stable", "oldstable", "tip", "x", "go1.22", "1.22.latest"} {
+		if err := Set(name, "1.22.6"); err == nil {
+			t.Errorf("Set(%q) should reject the name", name)
+		}
+	}
+	for _, target := range []string{"", "latest", "1.22.6.1", "prod"} {
+		if err := Set("prod", target); err == nil {
+			t.Errorf("Set(prod, %q) should reject the target", target)
+		}
+	}
+}
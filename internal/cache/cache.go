@@ -0,0 +1,226 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// Kinds of files kept in the cache directory.
+const (
+	KindArchive = "archive" // A verified Go archive
+	KindPartial = "partial" // An archive whose download has not finished yet
+	KindIndex   = "index"   // The cached remote version index
+)
+
+// Entry describes a single file in the cache.
+type Entry struct {
+	Path     string
+	Name     string
+	Kind     string
+	Checksum string // SHA-256 digest of an archive, or the "h1:" hash of a module zip
+	Size     int64
+	ModTime  time.Time
+}
+
+// DownloadsDir returns the directory holding downloaded archives.
+func DownloadsDir() string {
+	return filepath.Join(config.CacheDir, "downloads")
+}
+
+// ArchivePath returns the content-addressed cache location for an archive.
+// Archives are stored as downloads/<sha256>/<filename>, so a mirror serving
+// different content under the same name never collides with an existing entry.
+// Module zips verified by a go.sum style "h1:" hash are stored under
+// downloads/h1-<hex digest>/<filename> instead.
+//
+// Both values come from a remote index, so a filename that is not a plain file
+// name and a checksum that is not a SHA-256 digest are rejected rather than used
+// as path components.
+func ArchivePath(filename, checksum string) (string, error) {
+	if filename == "" || filename == "." || filepath.Base(filename) != filename || strings.Contains(filename, "..") {
+		return "", fmt.Errorf("invalid archive name %q", filename)
+	}
+	if h, ok := strings.CutPrefix(checksum, "h1:"); ok {
+		digest, err := base64.StdEncoding.DecodeString(h)
+		if err != nil || len(digest) != sha256.Size {
+			return "", fmt.Errorf("invalid checksum %q for %s", checksum, filename)
+		}
+		return filepath.Join(DownloadsDir(), "h1-"+hex.EncodeToString(digest), filename), nil
+	}
+	if !ValidSHA256(checksum) {
+		return "", fmt.Errorf("invalid checksum %q for %s", checksum, filename)
+	}
+	return filepath.Join(DownloadsDir(), strings.ToLower(checksum), filename), nil
+}
+
+// ValidSHA256 reports whether s is a hex encoded SHA-256 digest.
+func ValidSHA256(s string) bool {
+	digest, err := hex.DecodeString(s)
+	return err == nil && len(digest) == sha256.Size
+}
+
+// Touch marks a cached file as recently used so that Prune keeps it.
+func Touch(path string) {
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+}
+
+// List returns all files in the cache directory, sorted by path.
+func List() ([]Entry, error) {
+	var entries []Entry
+	err := filepath.WalkDir(config.CacheDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == config.CacheDir {
+				return filepath.SkipDir // Nothing cached yet
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		entries = append(entries, newEntry(path, fi))
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Path < entries[j].Path
+	})
+	return entries, nil
+}
+
+// Size returns the total size in bytes of all cached files.
+func Size() (int64, error) {
+	entries, err := List()
+	if err != nil {
+		return 0, err
+	}
+
+	var total int64
+	for _, e := range entries {
+		total += e.Size
+	}
+	return total, nil
+}
+
+// Prune removes cached files that have not been used for longer than olderThan
+// and returns the removed entries. Partial downloads are only removed if partial
+// is set, as another sgv process may still be writing to them.
+func Prune(olderThan time.Duration, partial bool) ([]Entry, error) {
+	entries, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	cutoff := time.Now().Add(-olderThan)
+	var removed []Entry
+	for _, e := range entries {
+		if !e.ModTime.Before(cutoff) || (e.Kind == KindPartial && !partial) {
+			continue
+		}
+		if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+			return removed, fmt.Errorf("failed to remove %s: %w", e.Path, err)
+		}
+		removed = append(removed, e)
+		removeEmptyParents(filepath.Dir(e.Path))
+	}
+	return removed, nil
+}
+
+// Clear removes everything from the cache directory. Partial downloads are only
+// removed if partial is set, as another sgv process may still be writing to them.
+func Clear(partial bool) error {
+	if !partial {
+		entries, err := List()
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if e.Kind == KindPartial {
+				continue
+			}
+			if err := os.Remove(e.Path); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to clear cache: %w", err)
+			}
+			removeEmptyParents(filepath.Dir(e.Path))
+		}
+		return nil
+	}
+
+	entries, err := os.ReadDir(config.CacheDir)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read cache directory: %w", err)
+	}
+
+	for _, entry := range entries {
+		if err := os.RemoveAll(filepath.Join(config.CacheDir, entry.Name())); err != nil {
+			return fmt.Errorf("failed to clear cache: %w", err)
+		}
+	}
+	return nil
+}
+
+// newEntry classifies a cached file by its location.
+func newEntry(path string, fi fs.FileInfo) Entry {
+	e := Entry{
+		Path:    path,
+		Name:    fi.Name(),
+		Kind:    KindIndex,
+		Size:    fi.Size(),
+		ModTime: fi.ModTime(),
+	}
+
+	if rel, err := filepath.Rel(DownloadsDir(), path); err == nil && !strings.HasPrefix(rel, "..") {
+		e.Checksum = dirChecksum(filepath.Dir(rel))
+		e.Kind = KindArchive
+		if strings.HasSuffix(e.Name, ".part") {
+			e.Name = strings.TrimSuffix(e.Name, ".part")
+			e.Kind = KindPartial
+		}
+	}
+	return e
+}
+
+// dirChecksum turns the name of a checksum directory made by ArchivePath back into
+// the checksum.
+func dirChecksum(dir string) string {
+	h, ok := strings.CutPrefix(dir, "h1-")
+	if !ok {
+		return dir
+	}
+	digest, err := hex.DecodeString(h)
+	if err != nil {
+		return dir
+	}
+	return "h1:" + base64.StdEncoding.EncodeToString(digest)
+}
+
+// removeEmptyParents removes dir and its parents up to the cache root while they are empty.
+func removeEmptyParents(dir string) {
+	for dir != config.CacheDir && strings.HasPrefix(dir, config.CacheDir) {
+		if err := os.Remove(dir); err != nil {
+			return // Not empty (or already gone)
+		}
+		dir = filepath.Dir(dir)
+	}
+}
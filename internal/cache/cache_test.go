@@ -0,0 +1,191 @@
+package cache
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+const testSHA = "9e2f2a4031b215922aa21a3695e30bbfa1f7707597834287415dbc862c6a3251"
+
+func setupCache(t *testing.T) {
+	t.Helper()
+	original := config.CacheDir
+	config.CacheDir = filepath.Join(t.TempDir(), "cache")
+	t.Cleanup(func() { config.CacheDir = original })
+}
+
+func writeCacheFile(t *testing.T, path string, size int, modTime time.Time) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatalf("chtimes %s: %v", path, err)
+	}
+}
+
+func archivePath(t *testing.T, filename, checksum string) string {
+	t.Helper()
+	path, err := ArchivePath(filename, checksum)
+	if err != nil {
+		t.Fatalf("ArchivePath failed: %v", err)
+	}
+	return path
+}
+
+func TestListAndSize(t *testing.T) {
+	setupCache(t)
+	now := time.Now()
+
+	writeCacheFile(t, archivePath(t, "go1.22.1.linux-amd64.tar.gz", testSHA), 100, now)
+	writeCacheFile(t, archivePath(t, "go1.22.2.linux-amd64.tar.gz", testSHA)+".part", 20, now)
+	writeCacheFile(t, filepath.Join(config.CacheDir, "remote-versions.json"), 3, now)
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+
+	kinds := make(map[string]Entry)
+	for _, e := range entries {
+		kinds[e.Kind] = e
+	}
+	if e := kinds[KindArchive]; e.Name != "go1.22.1.linux-amd64.tar.gz" || e.Checksum != testSHA {
+		t.Errorf("unexpected archive entry: %+v", e)
+	}
+	if e := kinds[KindPartial]; e.Name != "go1.22.2.linux-amd64.tar.gz" {
+		t.Errorf("unexpected partial entry: %+v", e)
+	}
+	if e := kinds[KindIndex]; e.Name != "remote-versions.json" {
+		t.Errorf("unexpected index entry: %+v", e)
+	}
+
+	size, err := Size()
+	if err != nil {
+		t.Fatalf("Size failed: %v", err)
+	}
+	if size != 123 {
+		t.Errorf("Size() = %d, want 123", size)
+	}
+}
+
+func TestListModuleZipChecksum(t *testing.T) {
+	setupCache(t)
+
+	const hash = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	writeCacheFile(t, archivePath(t, "v0.0.1-go1.22.1.linux-amd64.zip", hash), 10, time.Now())
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Kind != KindArchive || entries[0].Checksum != hash {
+		t.Errorf("unexpected entries %+v, want checksum %s", entries, hash)
+	}
+}
+
+func TestListEmptyCache(t *testing.T) {
+	setupCache(t)
+
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List on missing cache dir failed: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected no entries, got %d", len(entries))
+	}
+}
+
+func TestPrune(t *testing.T) {
+	setupCache(t)
+
+	oldArchive := archivePath(t, "go1.21.0.linux-amd64.tar.gz", testSHA)
+	newArchive := archivePath(t, "go1.22.1.linux-amd64.tar.gz", "0f4a"+testSHA[4:])
+	writeCacheFile(t, oldArchive, 10, time.Now().Add(-40*24*time.Hour))
+	writeCacheFile(t, newArchive, 10, time.Now())
+
+	oldPartial := archivePath(t, "go1.21.1.linux-amd64.tar.gz", testSHA) + ".part"
+	writeCacheFile(t, oldPartial, 10, time.Now().Add(-40*24*time.Hour))
+
+	removed, err := Prune(30*24*time.Hour, false)
+	if err != nil {
+		t.Fatalf("Prune failed: %v", err)
+	}
+	if len(removed) != 1 || removed[0].Path != oldArchive {
+		t.Fatalf("unexpected removed entries: %+v", removed)
+	}
+	if _, err := os.Stat(oldPartial); err != nil {
+		t.Errorf("partial download was removed without partial: %v", err)
+	}
+	if removed, err := Prune(30*24*time.Hour, true); err != nil || len(removed) != 1 || removed[0].Kind != KindPartial {
+		t.Fatalf("Prune with partial = %+v, %v; want the partial download", removed, err)
+	}
+	if _, err := os.Stat(filepath.Dir(oldArchive)); !os.IsNotExist(err) {
+		t.Errorf("expected empty checksum directory to be removed, got: %v", err)
+	}
+	if _, err := os.Stat(newArchive); err != nil {
+		t.Errorf("recent archive was removed: %v", err)
+	}
+}
+
+func TestClear(t *testing.T) {
+	setupCache(t)
+
+	writeCacheFile(t, archivePath(t, "go1.22.1.linux-amd64.tar.gz", testSHA), 10, time.Now())
+	writeCacheFile(t, filepath.Join(config.CacheDir, "remote-versions.json"), 10, time.Now())
+	partial := archivePath(t, "go1.22.2.linux-amd64.tar.gz", testSHA) + ".part"
+	writeCacheFile(t, partial, 10, time.Now())
+
+	if err := Clear(false); err != nil {
+		t.Fatalf("Clear failed: %v", err)
+	}
+	entries, err := List()
+	if err != nil {
+		t.Fatalf("List failed: %v", err)
+	}
+	if len(entries) != 1 || entries[0].Path != partial {
+		t.Errorf("expected only the partial download after Clear, got %+v", entries)
+	}
+
+	if err := Clear(true); err != nil {
+		t.Fatalf("Clear with partial failed: %v", err)
+	}
+	if entries, err := List(); err != nil || len(entries) != 0 {
+		t.Errorf("expected empty cache after Clear with partial, got %+v, %v", entries, err)
+	}
+}
+
+func TestArchivePathRejectsUnsafeValues(t *testing.T) {
+	setupCache(t)
+
+	tests := []struct {
+		filename, checksum string
+	}{
+		{"../../../.bashrc", testSHA},
+		{"sub/go1.22.1.linux-amd64.tar.gz", testSHA},
+		{"..", testSHA},
+		{"", testSHA},
+		{"go1.22.1.linux-amd64.tar.gz", "../../evil"},
+		{"go1.22.1.linux-amd64.tar.gz", testSHA[:63]},
+		{"go1.22.1.linux-amd64.tar.gz", "h1:bm90IGEgaGFzaA=="},
+		{"go1.22.1.linux-amd64.tar.gz", ""},
+	}
+	for _, tt := range tests {
+		if path, err := ArchivePath(tt.filename, tt.checksum); err == nil {
+			t.Errorf("ArchivePath(%q, %q) = %s, want an error", tt.filename, tt.checksum, path)
+		}
+	}
+
+	path, err := ArchivePath("go1.22.1.linux-amd64.tar.gz", "h1:"+strings.Repeat("A", 43)+"=")
+	if err != nil || !strings.HasPrefix(path, DownloadsDir()) {
+		t.Errorf("ArchivePath with an h1 hash = %s, %v", path, err)
+	}
+}
@@ -1,18 +1,62 @@
 package config
 
 import (
+	"bufio"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
 )
 
 var (
-	SgvRoot           string
-	VersionsDir       string
-	CurrentSymlink    string
-	DownloadURLPrefix string
+	SgvRoot          string
+	ConfigFile       string
+	AliasesFile      string
+	VersionsDir      string
+	StagingDir       string
+	CacheDir         string
+	LocksDir         string
+	CurrentSymlink   string
+	DownloadMirrors  []string
+	DownloadAttempts int
+	GoProxy          string
+	GoSumDB          string
+	Source           string
+	LockTimeout      time.Duration
+	Dedupe           bool
+	HTTPProxy        string
+	CAFile           string
+	ClientCert       string
+	ClientKey        string
+	HTTPTimeout      time.Duration
+	UserAgent        string
+	UpdateURL        string
 )
 
+const (
+	// defaultDownloadMirror is used when no download mirror is configured.
+	defaultDownloadMirror = "https://go.dev/dl/"
+	// defaultDownloadAttempts is how often a download is tried before giving up.
+	defaultDownloadAttempts = 5
+	// defaultGoProxy serves golang.org/toolchain modules when no proxy is configured.
+	defaultGoProxy = "https://proxy.golang.org"
+	// defaultGoSumDB is the checksum database used to verify toolchain modules.
+	defaultGoSumDB = "sum.golang.org"
+	// DefaultSource lists and downloads releases from the download mirrors.
+	DefaultSource = "godev"
+	// defaultLockTimeout is how long sgv waits for another sgv process to release a lock.
+	defaultLockTimeout = 10 * time.Minute
+	// DefaultUpdateURL is the release index sgv self-update checks for new sgv releases.
+	DefaultUpdateURL = "https://api.github.com/repos/fun7257/sgv/releases/latest"
+	// defaultHTTPTimeout bounds small HTTP requests and waiting for download responses.
+	defaultHTTPTimeout = 30 * time.Second
+)
+
+// fileSettings holds the settings read from ConfigFile.
+var fileSettings map[string]string
+
 func Init() {
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
@@ -21,17 +65,101 @@ func Init() {
 	}
 
 	SgvRoot = filepath.Join(homeDir, ".sgv")
+	ConfigFile = filepath.Join(SgvRoot, "config")
+	AliasesFile = filepath.Join(SgvRoot, "aliases.json")
 	VersionsDir = filepath.Join(SgvRoot, "versions")
+	StagingDir = filepath.Join(SgvRoot, "staging")
+	CacheDir = filepath.Join(SgvRoot, "cache")
+	LocksDir = filepath.Join(SgvRoot, "locks")
 	CurrentSymlink = filepath.Join(SgvRoot, "current")
 
-	// Set DownloadURLPrefix from env or default
-	DownloadURLPrefix = os.Getenv("SGV_DOWNLOAD_URL_PREFIX")
-	if DownloadURLPrefix == "" {
-		DownloadURLPrefix = "https://go.dev/dl/"
+	fileSettings, err = loadFile(ConfigFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: ignoring config file: %v\n", err)
+	}
+
+	// Set DownloadMirrors from env, config file or default
+	DownloadMirrors = ParseMirrors(Get("SGV_DOWNLOAD_URL_PREFIX"))
+	if len(DownloadMirrors) == 0 {
+		DownloadMirrors = []string{defaultDownloadMirror}
+	}
+
+	// Set DownloadAttempts from env, config file or default
+	DownloadAttempts = defaultDownloadAttempts
+	if v := Get("SGV_DOWNLOAD_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid SGV_DOWNLOAD_ATTEMPTS %q, using %d\n", v, defaultDownloadAttempts)
+		} else {
+			DownloadAttempts = n
+		}
+	}
+
+	// Set GoProxy and GoSumDB from sgv settings, the go command's own settings or defaults
+	GoProxy = ParseGoProxy(Get("SGV_GOPROXY"))
+	if GoProxy == "" {
+		GoProxy = ParseGoProxy(os.Getenv("GOPROXY"))
 	}
-	// Ensure ends with '/'
-	if DownloadURLPrefix[len(DownloadURLPrefix)-1] != '/' {
-		DownloadURLPrefix += "/"
+	if GoProxy == "" {
+		GoProxy = defaultGoProxy
+	}
+	GoSumDB = Get("SGV_GOSUMDB")
+	if GoSumDB == "" {
+		GoSumDB = os.Getenv("GOSUMDB")
+	}
+	if GoSumDB == "" {
+		GoSumDB = defaultGoSumDB
+	}
+
+	// Set Source from env, config file or default
+	Source = strings.TrimSpace(Get("SGV_SOURCE"))
+	if Source == "" {
+		Source = DefaultSource
+	}
+
+	// Set LockTimeout from env, config file or default
+	LockTimeout = defaultLockTimeout
+	if v := Get("SGV_LOCK_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < 0 {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid SGV_LOCK_TIMEOUT %q, using %s\n", v, defaultLockTimeout)
+		} else {
+			LockTimeout = d
+		}
+	}
+
+	// Set Dedupe from env or config file; off by default
+	Dedupe = false
+	if v := Get("SGV_DEDUPE"); v != "" {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid SGV_DEDUPE %q\n", v)
+		} else {
+			Dedupe = b
+		}
+	}
+
+	// Set the HTTP client settings from env or config file; by default the proxy
+	// comes from HTTPS_PROXY/HTTP_PROXY/NO_PROXY and only the system CAs are trusted
+	HTTPProxy = strings.TrimSpace(Get("SGV_HTTP_PROXY"))
+	CAFile = strings.TrimSpace(Get("SGV_CA_FILE"))
+	ClientCert = strings.TrimSpace(Get("SGV_CLIENT_CERT"))
+	ClientKey = strings.TrimSpace(Get("SGV_CLIENT_KEY"))
+	UserAgent = strings.TrimSpace(Get("SGV_USER_AGENT"))
+	HTTPTimeout = defaultHTTPTimeout
+	if v := Get("SGV_HTTP_TIMEOUT"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			fmt.Fprintf(os.Stderr, "Warning: ignoring invalid SGV_HTTP_TIMEOUT %q, using %s\n", v, defaultHTTPTimeout)
+		} else {
+			HTTPTimeout = d
+		}
+	}
+
+	// Set UpdateURL from env, config file or default
+	UpdateURL = strings.TrimSpace(Get("SGV_UPDATE_URL"))
+	if UpdateURL == "" {
+		UpdateURL = DefaultUpdateURL
 	}
 
 	for _, dir := range []string{SgvRoot, VersionsDir} {
@@ -43,3 +171,86 @@ func Init() {
 		}
 	}
 }
+
+// Get returns the value of a setting. Environment variables take precedence over
+// the config file, which uses the same names (e.g., SGV_DOWNLOAD_URL_PREFIX).
+func Get(key string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return fileSettings[key]
+}
+
+// ParseMirrors splits a comma or whitespace separated list of download URL
+// prefixes, ensuring each ends with '/'.
+func ParseMirrors(s string) []string {
+	var mirrors []string
+	for _, m := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		// Ensure ends with '/'
+		if !strings.HasSuffix(m, "/") {
+			m += "/"
+		}
+		mirrors = append(mirrors, m)
+	}
+	return mirrors
+}
+
+// ParseGoProxy returns the first proxy URL of a GOPROXY style list, skipping the
+// "direct" and "off" keywords, without a trailing '/'. It returns "" if the list
+// names no proxy.
+func ParseGoProxy(s string) string {
+	for _, p := range strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '|' || r == ' ' || r == '\t' || r == '\n'
+	}) {
+		if p == "direct" || p == "off" {
+			continue
+		}
+		return strings.TrimSuffix(p, "/")
+	}
+	return ""
+}
+
+// loadFile reads KEY=VALUE settings from path. Empty lines and lines starting with
+// '#' are ignored, and values may be wrapped in quotes. A missing file yields no settings.
+func loadFile(path string) (map[string]string, error) {
+	settings := make(map[string]string)
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return settings, nil
+	}
+	if err != nil {
+		return settings, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+
+		// Skip empty lines and comments
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return settings, fmt.Errorf("invalid format at %s:%d: %s", path, lineNum, line)
+		}
+
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		settings[strings.TrimSpace(key)] = value
+	}
+
+	if err := scanner.Err(); err != nil {
+		return settings, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return settings, nil
+}
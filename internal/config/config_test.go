@@ -0,0 +1,104 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseMirrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"empty", "", nil},
+		{"single", "https://go.dev/dl/", []string{"https://go.dev/dl/"}},
+		{"adds trailing slash", "https://golang.google.cn/dl", []string{"https://golang.google.cn/dl/"}},
+		{"comma separated", "https://golang.google.cn/dl/,https://go.dev/dl/", []string{"https://golang.google.cn/dl/", "https://go.dev/dl/"}},
+		{"spaces and empty items", " https://a/dl , ,https://b/dl ", []string{"https://a/dl/", "https://b/dl/"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseMirrors(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseMirrors(%q) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseGoProxy(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"empty", "", ""},
+		{"single", "https://proxy.golang.org", "https://proxy.golang.org"},
+		{"strips trailing slash", "https://athens.example.com/", "https://athens.example.com"},
+		{"skips keywords", "direct,https://goproxy.cn,https://proxy.golang.org", "https://goproxy.cn"},
+		{"pipe separated", "https://athens.example.com|https://proxy.golang.org", "https://athens.example.com"},
+		{"only keywords", "off", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ParseGoProxy(tt.input); got != tt.want {
+				t.Errorf("ParseGoProxy(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	content := `# sgv configuration
+SGV_DOWNLOAD_URL_PREFIX=https://golang.google.cn/dl/,https://go.dev/dl/
+
+SGV_DOWNLOAD_ATTEMPTS = "3"
+SGV_EMPTY=
+`
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	settings, err := loadFile(path)
+	if err != nil {
+		t.Fatalf("loadFile failed: %v", err)
+	}
+	want := map[string]string{
+		"SGV_DOWNLOAD_URL_PREFIX": "https://golang.google.cn/dl/,https://go.dev/dl/",
+		"SGV_DOWNLOAD_ATTEMPTS":   "3",
+		"SGV_EMPTY":               "",
+	}
+	if !reflect.DeepEqual(settings, want) {
+		t.Errorf("loadFile() = %v, want %v", settings, want)
+	}
+
+	if settings, err := loadFile(filepath.Join(t.TempDir(), "missing")); err != nil || len(settings) != 0 {
+		t.Errorf("expected no settings and no error for missing file, got %v, %v", settings, err)
+	}
+
+	if err := os.WriteFile(path, []byte("NOT A SETTING\n"), 0644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := loadFile(path); err == nil {
+		t.Errorf("expected error for malformed line")
+	}
+}
+
+func TestGetPrefersEnvironment(t *testing.T) {
+	original := fileSettings
+	fileSettings = map[string]string{"SGV_TEST_SETTING": "from-file", "SGV_TEST_FILE_ONLY": "file"}
+	t.Cleanup(func() { fileSettings = original })
+	t.Setenv("SGV_TEST_SETTING", "from-env")
+
+	if got := Get("SGV_TEST_SETTING"); got != "from-env" {
+		t.Errorf("Get() = %q, want value from environment", got)
+	}
+	if got := Get("SGV_TEST_FILE_ONLY"); got != "file" {
+		t.Errorf("Get() = %q, want value from config file", got)
+	}
+}
@@ -0,0 +1,207 @@
+// Package dedupe saves disk space by replacing identical files in installed Go
+// versions with hard links to a single copy.
+//
+// Patch releases of the same minor version share most of their files. Hard links
+// are reference counted, so removing one version never affects the files of another.
+package dedupe
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
+)
+
+// Result summarizes a deduplication run.
+type Result struct {
+	Linked int   // Files replaced by a hard link
+	Saved  int64 // Bytes freed
+}
+
+// Versions deduplicates files across the installations of versions, holding the
+// install lock of every version while it runs.
+func Versions(versions []string) (Result, error) {
+	// Lock in a fixed order so concurrent runs cannot deadlock, and each version only once
+	names := append([]string(nil), versions...)
+	sort.Strings(names)
+	names = slices.Compact(names)
+
+	var dirs []string
+	for _, v := range names {
+		l, err := lock.Version(v)
+		if err != nil {
+			return Result{}, err
+		}
+		defer l.Release()
+		dirs = append(dirs, filepath.Join(config.VersionsDir, v))
+	}
+	return run(dirs, len(dirs))
+}
+
+// Into replaces files of target with hard links to identical files in others,
+// leaving the others untouched. The caller must hold the install lock of target.
+func Into(target string, others []string) (Result, error) {
+	var dirs []string
+	for _, v := range others {
+		dirs = append(dirs, filepath.Join(config.VersionsDir, v))
+	}
+	dirs = append(dirs, filepath.Join(config.VersionsDir, target))
+	return run(dirs, 1)
+}
+
+// file is a regular file found in one of the scanned directories.
+type file struct {
+	path     string
+	size     int64
+	mode     fs.FileMode
+	inode    inode
+	nlink    uint64
+	writable bool // Whether this file may be replaced
+}
+
+type inode struct {
+	dev, ino uint64
+}
+
+// groupKey groups files that can share an inode: same size and permissions.
+type groupKey struct {
+	size int64
+	mode fs.FileMode
+}
+
+// run deduplicates the files below dirs. Only files in the last writable of the
+// dirs are replaced; earlier directories merely provide link targets.
+func run(dirs []string, writable int) (Result, error) {
+	groups := make(map[groupKey][]*file)
+	for i, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.Type().IsRegular() {
+				return nil
+			}
+			fi, err := d.Info()
+			if err != nil {
+				return err
+			}
+			ino, nlink, ok := fileInode(fi)
+			if !ok || fi.Size() == 0 {
+				return nil
+			}
+			f := &file{
+				path:     path,
+				size:     fi.Size(),
+				mode:     fi.Mode().Perm(),
+				inode:    ino,
+				nlink:    nlink,
+				writable: i >= len(dirs)-writable,
+			}
+			key := groupKey{f.size, f.mode}
+			groups[key] = append(groups[key], f)
+			return nil
+		})
+		if err != nil {
+			return Result{}, fmt.Errorf("failed to scan %s: %w", dir, err)
+		}
+	}
+
+	var result Result
+	remaining := make(map[inode]uint64) // Links left per inode, to tell when its data is freed
+	for _, files := range groups {
+		if len(files) < 2 {
+			continue
+		}
+
+		// Files already sharing an inode need to be hashed only once
+		byHash := make(map[[sha256.Size]byte][]*file)
+		hashes := make(map[inode][sha256.Size]byte)
+		for _, f := range files {
+			sum, ok := hashes[f.inode]
+			if !ok {
+				var err error
+				if sum, err = hashFile(f.path); err != nil {
+					return result, err
+				}
+				hashes[f.inode] = sum
+			}
+			byHash[sum] = append(byHash[sum], f)
+		}
+
+		for _, same := range byHash {
+			if err := link(same, remaining, &result); err != nil {
+				return result, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// link replaces every writable file in same with a hard link to a single copy, taken
+// from a directory that must not be modified if there is one. Files on other
+// devices are left alone.
+func link(same []*file, remaining map[inode]uint64, result *Result) error {
+	target := same[0]
+	for _, f := range same {
+		if !f.writable {
+			target = f
+			break
+		}
+	}
+
+	for _, f := range same {
+		if !f.writable || f.inode == target.inode || f.inode.dev != target.inode.dev {
+			continue
+		}
+		if err := replaceWithLink(target.path, f.path); err != nil {
+			return err
+		}
+		result.Linked++
+
+		if _, ok := remaining[f.inode]; !ok {
+			remaining[f.inode] = f.nlink
+		}
+		remaining[f.inode]--
+		if remaining[f.inode] == 0 {
+			result.Saved += f.size
+		}
+		f.inode = target.inode
+	}
+	return nil
+}
+
+// replaceWithLink atomically replaces path with a hard link to target.
+func replaceWithLink(target, path string) error {
+	tmp := filepath.Join(filepath.Dir(path), fmt.Sprintf(".%s.sgv-link-%d", filepath.Base(path), os.Getpid()))
+	if err := os.Link(target, tmp); err != nil {
+		return fmt.Errorf("failed to link %s: %w", path, err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
+
+func hashFile(path string) ([sha256.Size]byte, error) {
+	var sum [sha256.Size]byte
+	f, err := os.Open(path)
+	if err != nil {
+		return sum, fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return sum, fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	copy(sum[:], h.Sum(nil))
+	return sum, nil
+}
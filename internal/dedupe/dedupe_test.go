@@ -0,0 +1,125 @@
+package dedupe
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+func setupVersions(t *testing.T) {
+	t.Helper()
+	tmp := t.TempDir()
+	originalVersions, originalLocks := config.VersionsDir, config.LocksDir
+	config.VersionsDir = filepath.Join(tmp, "versions")
+	config.LocksDir = filepath.Join(tmp, "locks")
+	t.Cleanup(func() { config.VersionsDir, config.LocksDir = originalVersions, originalLocks })
+
+	files := map[string]struct {
+		content string
+		mode    os.FileMode
+	}{
+		"go1.22.0/go/src/fmt/print.go":  {"package fmt // shared", 0644},
+		"go1.22.1/go/src/fmt/print.go":  {"package fmt // shared", 0644},
+		"go1.22.0/go/VERSION":           {"go1.22.0", 0644},
+		"go1.22.1/go/VERSION":           {"go1.22.1", 0644},
+		"go1.22.0/go/bin/tool":          {"same content", 0755},
+		"go1.22.1/go/bin/tool":          {"same content", 0644}, // Different mode, must stay separate
+		"go1.22.0/go/src/empty.go":      {"", 0644},
+		"go1.22.1/go/src/empty.go":      {"", 0644},
+		"go1.22.1/go/src/fmt/format.go": {"package fmt // shared", 0644},
+	}
+	for name, f := range files {
+		path := filepath.Join(config.VersionsDir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(f.content), f.mode); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+		if err := os.Chmod(path, f.mode); err != nil {
+			t.Fatalf("chmod %s: %v", name, err)
+		}
+	}
+}
+
+func sameFile(t *testing.T, a, b string) bool {
+	t.Helper()
+	fa, err := os.Stat(filepath.Join(config.VersionsDir, a))
+	if err != nil {
+		t.Fatalf("stat %s: %v", a, err)
+	}
+	fb, err := os.Stat(filepath.Join(config.VersionsDir, b))
+	if err != nil {
+		t.Fatalf("stat %s: %v", b, err)
+	}
+	return os.SameFile(fa, fb)
+}
+
+func TestVersions(t *testing.T) {
+	setupVersions(t)
+
+	result, err := Versions([]string{"go1.22.1", "go1.22.0"})
+	if err != nil {
+		t.Fatalf("Versions failed: %v", err)
+	}
+
+	// The three identical sources share one inode
+	if !sameFile(t, "go1.22.0/go/src/fmt/print.go", "go1.22.1/go/src/fmt/print.go") ||
+		!sameFile(t, "go1.22.0/go/src/fmt/print.go", "go1.22.1/go/src/fmt/format.go") {
+		t.Error("identical files were not linked")
+	}
+	if sameFile(t, "go1.22.0/go/VERSION", "go1.22.1/go/VERSION") {
+		t.Error("different files were linked")
+	}
+	if sameFile(t, "go1.22.0/go/bin/tool", "go1.22.1/go/bin/tool") {
+		t.Error("files with different modes were linked")
+	}
+	if result.Linked != 2 || result.Saved != 2*int64(len("package fmt // shared")) {
+		t.Errorf("result = %+v", result)
+	}
+
+	// Running again finds nothing left to do
+	if result, err := Versions([]string{"go1.22.0", "go1.22.1"}); err != nil || result.Linked != 0 {
+		t.Errorf("second run = %+v, %v", result, err)
+	}
+
+	// Removing one version leaves the other intact
+	if err := os.RemoveAll(filepath.Join(config.VersionsDir, "go1.22.0")); err != nil {
+		t.Fatalf("remove: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(config.VersionsDir, "go1.22.1/go/src/fmt/print.go"))
+	if err != nil || string(data) != "package fmt // shared" {
+		t.Errorf("linked file after removal = %q, %v", data, err)
+	}
+}
+
+func TestIntoOnlyChangesTarget(t *testing.T) {
+	setupVersions(t)
+
+	before, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.0/go/src/fmt/print.go"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+
+	result, err := Into("go1.22.1", []string{"go1.22.0"})
+	if err != nil {
+		t.Fatalf("Into failed: %v", err)
+	}
+	if result.Linked != 2 {
+		t.Errorf("result = %+v", result)
+	}
+
+	// The existing version keeps its inode; the new one links to it
+	after, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.0/go/src/fmt/print.go"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if !os.SameFile(before, after) {
+		t.Error("Into replaced a file outside the target version")
+	}
+	if !sameFile(t, "go1.22.0/go/src/fmt/print.go", "go1.22.1/go/src/fmt/format.go") {
+		t.Error("target files were not linked to the existing version")
+	}
+}
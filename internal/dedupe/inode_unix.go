@@ -0,0 +1,17 @@
+//go:build unix
+
+package dedupe
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// fileInode returns the inode of fi and its number of hard links.
+func fileInode(fi fs.FileInfo) (inode, uint64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return inode{}, 0, false
+	}
+	return inode{uint64(st.Dev), uint64(st.Ino)}, uint64(st.Nlink), true
+}
@@ -11,6 +11,7 @@ import (
 	"sync"
 
 	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
 	"github.com/fun7257/sgv/internal/version"
 )
 
@@ -118,6 +119,17 @@ func LoadEnvVars(version string) (EnvVars, error) {
 
 // SaveEnvVars saves environment variables for the given version
 func SaveEnvVars(version string, vars EnvVars) error {
+	l, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	return saveEnvVars(version, vars)
+}
+
+// saveEnvVars writes the env file of version. The caller must hold the global lock.
+func saveEnvVars(version string, vars EnvVars) error {
 	envDir := GetEnvDir()
 	envFile := GetEnvFile(version)
 
@@ -180,6 +192,13 @@ func SetEnvVar(version, key, value string) error {
 		return fmt.Errorf("%s is a protected environment variable and cannot be modified", key)
 	}
 
+	// Hold the global lock so that concurrent sgv processes don't lose each other's updates
+	l, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
 	// Load existing variables
 	vars, err := LoadEnvVars(version)
 	if err != nil {
@@ -190,7 +209,7 @@ func SetEnvVar(version, key, value string) error {
 	vars[key] = value
 
 	// Save variables
-	if err := SaveEnvVars(version, vars); err != nil {
+	if err := saveEnvVars(version, vars); err != nil {
 		return fmt.Errorf("failed to save variables: %w", err)
 	}
 
@@ -209,6 +228,13 @@ func UnsetEnvVar(version, key string) error {
 		return fmt.Errorf("%s is a protected environment variable and cannot be removed", key)
 	}
 
+	// Hold the global lock so that concurrent sgv processes don't lose each other's updates
+	l, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
 	// Load existing variables
 	vars, err := LoadEnvVars(version)
 	if err != nil {
@@ -224,7 +250,7 @@ func UnsetEnvVar(version, key string) error {
 	delete(vars, key)
 
 	// Save variables
-	if err := SaveEnvVars(version, vars); err != nil {
+	if err := saveEnvVars(version, vars); err != nil {
 		return fmt.Errorf("failed to save variables: %w", err)
 	}
 
@@ -233,6 +259,13 @@ func UnsetEnvVar(version, key string) error {
 
 // ClearAllEnvVars removes all environment variables for the given version
 func ClearAllEnvVars(version string) error {
+	// Hold the global lock so that concurrent sgv processes don't lose each other's updates
+	l, err := lock.Global()
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
 	// Load existing variables to check if any exist
 	vars, err := LoadEnvVars(version)
 	if err != nil {
@@ -245,7 +278,7 @@ func ClearAllEnvVars(version string) error {
 
 	// Clear all variables by saving an empty map
 	emptyVars := make(EnvVars)
-	if err := SaveEnvVars(version, emptyVars); err != nil {
+	if err := saveEnvVars(version, emptyVars); err != nil {
 		return fmt.Errorf("failed to clear variables: %w", err)
 	}
 
@@ -258,6 +291,70 @@ func ClearAllEnvVars(version string) error {
 	return nil
 }
 
+// Migration summarizes the environment variables carried over from one version to another.
+type Migration struct {
+	Added     int      // Variables set for the target that it did not have
+	Conflicts []string // Variables set for both versions with different values, sorted
+}
+
+// CopyEnvVars copies the environment variables of version from to version to,
+// e.g. when upgrading to a new patch release. Variables already set for to keep
+// their value; those set differently for from are reported as conflicts.
+func CopyEnvVars(from, to string) (Migration, error) {
+	return migrateEnvVars(from, to, false)
+}
+
+// MoveEnvVars is like CopyEnvVars, but removes the env file of from afterwards,
+// e.g. when from is about to be uninstalled.
+func MoveEnvVars(from, to string) (Migration, error) {
+	return migrateEnvVars(from, to, true)
+}
+
+func migrateEnvVars(from, to string, removeFrom bool) (Migration, error) {
+	l, err := lock.Global()
+	if err != nil {
+		return Migration{}, err
+	}
+	defer l.Release()
+
+	fromVars, err := LoadEnvVars(from)
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to load variables of %s: %w", from, err)
+	}
+	if len(fromVars) == 0 {
+		return Migration{}, nil
+	}
+
+	toVars, err := LoadEnvVars(to)
+	if err != nil {
+		return Migration{}, fmt.Errorf("failed to load variables of %s: %w", to, err)
+	}
+	var m Migration
+	for key, value := range fromVars {
+		existing, exists := toVars[key]
+		switch {
+		case !exists:
+			toVars[key] = value
+			m.Added++
+		case existing != value:
+			m.Conflicts = append(m.Conflicts, key)
+		}
+	}
+	sort.Strings(m.Conflicts)
+
+	if m.Added > 0 {
+		if err := saveEnvVars(to, toVars); err != nil {
+			return Migration{}, err
+		}
+	}
+	if removeFrom {
+		if err := os.Remove(GetEnvFile(from)); err != nil && !os.IsNotExist(err) {
+			return Migration{}, fmt.Errorf("failed to remove env file: %w", err)
+		}
+	}
+	return m, nil
+}
+
 // GetCurrentVersion returns the current active Go version
 func GetCurrentVersion() (string, error) {
 	currentVersion, err := version.GetCurrentVersion()
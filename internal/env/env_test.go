@@ -4,8 +4,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/fun7257/sgv/internal/config"
 )
@@ -87,11 +89,13 @@ func setupTestEnv(t *testing.T) (string, func()) {
 	}
 
 	// Backup original config
-	originalSgvRoot := config.SgvRoot
+	originalSgvRoot, originalLocksDir, originalLockTimeout := config.SgvRoot, config.LocksDir, config.LockTimeout
 	config.SgvRoot = tmpDir
+	config.LocksDir = filepath.Join(tmpDir, "locks")
+	config.LockTimeout = time.Minute
 
 	cleanup := func() {
-		config.SgvRoot = originalSgvRoot
+		config.SgvRoot, config.LocksDir, config.LockTimeout = originalSgvRoot, originalLocksDir, originalLockTimeout
 		os.RemoveAll(tmpDir)
 	}
 
@@ -825,3 +829,59 @@ func TestSetAndUnsetEnvVarErrorCoverage(t *testing.T) {
 		os.RemoveAll(envFile)
 	})
 }
+
+func TestCopyEnvVars(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if m, err := CopyEnvVars("go1.22.1", "go1.22.6"); err != nil || m.Added != 0 {
+		t.Fatalf("CopyEnvVars without env file = %+v, %v; want nothing added", m, err)
+	}
+
+	if err := SaveEnvVars("go1.22.1", EnvVars{"CGO_CFLAGS": "-O2", "MY_VAR": "old", "SAME": "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveEnvVars("go1.22.6", EnvVars{"MY_VAR": "new", "SAME": "x"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := CopyEnvVars("go1.22.1", "go1.22.6")
+	if err != nil {
+		t.Fatalf("CopyEnvVars failed: %v", err)
+	}
+	if m.Added != 1 || !reflect.DeepEqual(m.Conflicts, []string{"MY_VAR"}) {
+		t.Errorf("CopyEnvVars = %+v, want 1 added and a conflict on MY_VAR", m)
+	}
+
+	vars, err := LoadEnvVars("go1.22.6")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vars["CGO_CFLAGS"] != "-O2" || vars["MY_VAR"] != "new" {
+		t.Errorf("copied variables = %v, want CGO_CFLAGS=-O2 and MY_VAR=new", vars)
+	}
+	old, err := LoadEnvVars("go1.22.1")
+	if err != nil || len(old) != 3 {
+		t.Errorf("expected the old env file to be kept, got %v, %v", old, err)
+	}
+}
+
+func TestMoveEnvVars(t *testing.T) {
+	_, cleanup := setupTestEnv(t)
+	defer cleanup()
+
+	if err := SaveEnvVars("go1.22.1", EnvVars{"CGO_CFLAGS": "-O2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := MoveEnvVars("go1.22.1", "go1.22.6")
+	if err != nil || m.Added != 1 || len(m.Conflicts) != 0 {
+		t.Fatalf("MoveEnvVars = %+v, %v; want 1 added", m, err)
+	}
+	if vars, err := LoadEnvVars("go1.22.6"); err != nil || vars["CGO_CFLAGS"] != "-O2" {
+		t.Errorf("moved variables = %v, %v; want CGO_CFLAGS=-O2", vars, err)
+	}
+	if _, err := os.Stat(GetEnvFile("go1.22.1")); !os.IsNotExist(err) {
+		t.Errorf("expected the old env file to be removed, got %v", err)
+	}
+}
@@ -0,0 +1,226 @@
+// Package httpclient provides the HTTP clients every network request of sgv goes
+// through, configured from the sgv settings: proxy, extra CA certificates, a TLS
+// client certificate, timeouts and the User-Agent.
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// settings are the config values a client is built from.
+type settings struct {
+	proxy, caFile, clientCert, clientKey, userAgent string
+	timeout                                         time.Duration
+}
+
+func current() settings {
+	return settings{
+		proxy:      config.HTTPProxy,
+		caFile:     config.CAFile,
+		clientCert: config.ClientCert,
+		clientKey:  config.ClientKey,
+		userAgent:  config.UserAgent,
+		timeout:    config.HTTPTimeout,
+	}
+}
+
+// fallbackTimeout is used when config.HTTPTimeout is not set, as before config.Init.
+const fallbackTimeout = 30 * time.Second
+
+var (
+	mu        sync.Mutex
+	built     settings
+	transport http.RoundTripper // Shared by both clients, so connections are reused
+)
+
+// Client returns the client for small requests such as release indexes and
+// checksum database lookups. Each request is bounded by config.HTTPTimeout.
+func Client() (*http.Client, error) {
+	t, err := sharedTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t, Timeout: config.HTTPTimeout}, nil
+}
+
+// DownloadClient returns the client for archive downloads. Connecting and waiting
+// for the response are bounded by config.HTTPTimeout, reading the body is not.
+func DownloadClient() (*http.Client, error) {
+	t, err := sharedTransport()
+	if err != nil {
+		return nil, err
+	}
+	return &http.Client{Transport: t}, nil
+}
+
+// sharedTransport returns the transport for the current settings, building it on
+// first use and whenever the settings changed.
+func sharedTransport() (http.RoundTripper, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	s := current()
+	if transport != nil && s == built {
+		return transport, nil
+	}
+	t, err := newTransport(s)
+	if err != nil {
+		return nil, err
+	}
+	transport, built = t, s
+	return transport, nil
+}
+
+// newTransport builds a transport from s.
+func newTransport(s settings) (http.RoundTripper, error) {
+	proxy, err := proxyFunc(s.proxy)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := newTLSConfig(s)
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := s.timeout
+	if timeout <= 0 {
+		timeout = fallbackTimeout
+	}
+	t := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           (&net.Dialer{Timeout: timeout, KeepAlive: 30 * time.Second}).DialContext,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   timeout,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: time.Second,
+		IdleConnTimeout:       90 * time.Second,
+		MaxIdleConns:          100,
+		ForceAttemptHTTP2:     true,
+	}
+
+	userAgent := s.userAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+	return &userAgentTransport{base: t, userAgent: userAgent}, nil
+}
+
+// proxyFunc returns the proxy selection for SGV_HTTP_PROXY: a proxy URL, "direct"
+// to connect without proxy, or "" to use HTTPS_PROXY, HTTP_PROXY and NO_PROXY.
+func proxyFunc(proxy string) (func(*http.Request) (*url.URL, error), error) {
+	switch proxy {
+	case "":
+		return http.ProxyFromEnvironment, nil
+	case "direct":
+		return nil, nil
+	}
+
+	u, err := url.Parse(proxy)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid SGV_HTTP_PROXY %q: must be a URL such as http://proxy.example.com:3128 or 'direct'", proxy)
+	}
+	return http.ProxyURL(u), nil
+}
+
+// newTLSConfig trusts the system CAs plus those in s.caFile, and presents the client
+// certificate in s.clientCert (with the key in s.clientKey, or the same file) if set.
+func newTLSConfig(s settings) (*tls.Config, error) {
+	c := &tls.Config{}
+
+	if s.caFile != "" {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		pem, err := os.ReadFile(s.caFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read CA file: %w", err)
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no PEM certificates found in CA file %s", s.caFile)
+		}
+		c.RootCAs = pool
+	}
+
+	switch {
+	case s.clientCert != "":
+		key := s.clientKey
+		if key == "" {
+			key = s.clientCert
+		}
+		cert, err := tls.LoadX509KeyPair(s.clientCert, key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		c.Certificates = []tls.Certificate{cert}
+	case s.clientKey != "":
+		return nil, fmt.Errorf("SGV_CLIENT_KEY is set without SGV_CLIENT_CERT")
+	}
+	return c, nil
+}
+
+// DefaultUserAgent returns the User-Agent sent unless SGV_USER_AGENT is set, e.g. sgv/v1.4.0.
+func DefaultUserAgent() string {
+	v := "dev"
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" && info.Main.Version != "(devel)" {
+		v = info.Main.Version
+	}
+	return "sgv/" + v
+}
+
+// userAgentTransport sets the User-Agent of requests that have none.
+type userAgentTransport struct {
+	base      http.RoundTripper
+	userAgent string
+}
+
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Header.Get("User-Agent") == "" {
+		req = req.Clone(req.Context())
+		req.Header.Set("User-Agent", t.userAgent)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// GitConfig returns "git -c" arguments applying the settings to git's own HTTP(S)
+// transport, for cloning remote repositories. git replaces its default CAs with
+// those in the CA file rather than adding to them.
+func GitConfig() []string {
+	var args []string
+	add := func(key, value string) {
+		if value != "" {
+			args = append(args, "-c", key+"="+value)
+		}
+	}
+	if config.HTTPProxy == "direct" {
+		args = append(args, "-c", "http.proxy=")
+	} else {
+		add("http.proxy", config.HTTPProxy)
+	}
+	add("http.sslCAInfo", config.CAFile)
+	add("http.sslCert", config.ClientCert)
+	if config.ClientCert != "" {
+		key := config.ClientKey
+		if key == "" {
+			key = config.ClientCert
+		}
+		add("http.sslKey", key)
+	}
+	userAgent := config.UserAgent
+	if userAgent == "" {
+		userAgent = DefaultUserAgent()
+	}
+	add("http.userAgent", userAgent)
+	return args
+}
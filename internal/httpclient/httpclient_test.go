@@ -0,0 +1,219 @@
+package httpclient
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// setupConfig resets the HTTP settings and restores them after the test.
+func setupConfig(t *testing.T) {
+	t.Helper()
+	proxy, caFile, cert, key, timeout, userAgent := config.HTTPProxy, config.CAFile, config.ClientCert, config.ClientKey, config.HTTPTimeout, config.UserAgent
+	t.Cleanup(func() {
+		config.HTTPProxy, config.CAFile, config.ClientCert, config.ClientKey, config.HTTPTimeout, config.UserAgent = proxy, caFile, cert, key, timeout, userAgent
+	})
+	config.HTTPProxy, config.CAFile, config.ClientCert, config.ClientKey, config.UserAgent = "direct", "", "", "", ""
+	config.HTTPTimeout = 5 * time.Second
+}
+
+func get(t *testing.T, url string) (*http.Response, error) {
+	t.Helper()
+	client, err := Client()
+	if err != nil {
+		t.Fatalf("Client failed: %v", err)
+	}
+	resp, err := client.Get(url)
+	if err == nil {
+		resp.Body.Close()
+	}
+	return resp, err
+}
+
+func TestUserAgent(t *testing.T) {
+	setupConfig(t)
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+	}))
+	defer server.Close()
+
+	if _, err := get(t, server.URL); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got != DefaultUserAgent() || !strings.HasPrefix(got, "sgv/") {
+		t.Errorf("User-Agent = %q, want %q", got, DefaultUserAgent())
+	}
+
+	// Changed settings take effect without a restart
+	config.UserAgent = "corp-sgv/1.0"
+	if _, err := get(t, server.URL); err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	if got != "corp-sgv/1.0" {
+		t.Errorf("User-Agent = %q, want corp-sgv/1.0", got)
+	}
+}
+
+func TestProxy(t *testing.T) {
+	setupConfig(t)
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	config.HTTPProxy = proxy.URL
+	if _, err := get(t, "http://releases.example.invalid/dl/?mode=json"); err != nil {
+		t.Fatalf("request through proxy failed: %v", err)
+	}
+	if proxied != "http://releases.example.invalid/dl/?mode=json" {
+		t.Errorf("proxy received %q", proxied)
+	}
+}
+
+func TestCAFile(t *testing.T) {
+	setupConfig(t)
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer server.Close()
+
+	if _, err := get(t, server.URL); err == nil {
+		t.Fatal("expected the test server's certificate to be rejected without a CA file")
+	}
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	config.CAFile = caFile
+	if _, err := get(t, server.URL); err != nil {
+		t.Errorf("request with CA file failed: %v", err)
+	}
+}
+
+func TestClientCertificate(t *testing.T) {
+	setupConfig(t)
+	var peers int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		peers = len(r.TLS.PeerCertificates)
+	}))
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAnyClientCert}
+	server.StartTLS()
+	defer server.Close()
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	writePEM(t, caFile, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	config.CAFile = caFile
+	if _, err := get(t, server.URL); err == nil {
+		t.Fatal("expected the server to require a client certificate")
+	}
+
+	// Certificate and key in a single file
+	config.ClientCert = writeClientCert(t)
+	if _, err := get(t, server.URL); err != nil {
+		t.Fatalf("request with client certificate failed: %v", err)
+	}
+	if peers != 1 {
+		t.Errorf("server saw %d client certificates, want 1", peers)
+	}
+}
+
+func TestInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		set  func()
+		want string
+	}{
+		{"proxy", func() { config.HTTPProxy = "proxy.example.com:3128" }, "invalid SGV_HTTP_PROXY"},
+		{"missing CA file", func() { config.CAFile = "/nonexistent/ca.pem" }, "failed to read CA file"},
+		{"empty CA file", func() {
+			config.CAFile = filepath.Join(t.TempDir(), "ca.pem")
+			os.WriteFile(config.CAFile, []byte("not a certificate\n"), 0644)
+		}, "no PEM certificates"},
+		{"key without certificate", func() { config.ClientKey = "/etc/sgv/client.key" }, "SGV_CLIENT_KEY is set without SGV_CLIENT_CERT"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupConfig(t)
+			tt.set()
+			if _, err := Client(); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("Client() error = %v, want %q", err, tt.want)
+			}
+			if _, err := DownloadClient(); err == nil {
+				t.Error("DownloadClient() succeeded with invalid settings")
+			}
+		})
+	}
+}
+
+func TestGitConfig(t *testing.T) {
+	setupConfig(t)
+	config.HTTPProxy = "http://proxy.example.com:3128"
+	config.CAFile = "/etc/ssl/corp.pem"
+	config.ClientCert = "/etc/sgv/client.pem"
+	config.UserAgent = "corp-sgv/1.0"
+
+	want := []string{
+		"-c", "http.proxy=http://proxy.example.com:3128",
+		"-c", "http.sslCAInfo=/etc/ssl/corp.pem",
+		"-c", "http.sslCert=/etc/sgv/client.pem",
+		"-c", "http.sslKey=/etc/sgv/client.pem",
+		"-c", "http.userAgent=corp-sgv/1.0",
+	}
+	if got := GitConfig(); !reflect.DeepEqual(got, want) {
+		t.Errorf("GitConfig() = %q, want %q", got, want)
+	}
+}
+
+func writePEM(t *testing.T, path string, blocks ...*pem.Block) {
+	t.Helper()
+	var data []byte
+	for _, b := range blocks {
+		data = append(data, pem.EncodeToMemory(b)...)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+// writeClientCert writes a self-signed client certificate and its key to one file.
+func writeClientCert(t *testing.T) string {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sgv test client"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "client.pem")
+	writePEM(t, path, &pem.Block{Type: "CERTIFICATE", Bytes: der}, &pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return path
+}
@@ -0,0 +1,252 @@
+package installer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/httpclient"
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// DefaultGoRepo is the Go repository cloned by InstallFromSource when no repository is given.
+const DefaultGoRepo = "https://go.googlesource.com/go"
+
+// SourceOptions configures InstallFromSource.
+type SourceOptions struct {
+	Ref       string // Git ref (branch, tag or commit) to build; empty means master (tip)
+	Repo      string // Local checkout or remote URL of the Go repository; empty means DefaultGoRepo
+	Bootstrap string // Installed version to use as GOROOT_BOOTSTRAP; empty means the newest installed release
+}
+
+// InstallFromSource builds Go from a git ref with make.bash and installs the result
+// under the name gotip-<shortsha>, which is returned.
+//
+// A local checkout given as opts.Repo is only read from. Remote repositories are
+// cloned once into the cache directory and fetched on subsequent builds.
+func InstallFromSource(opts SourceOptions) (string, error) {
+	if _, err := exec.LookPath("git"); err != nil {
+		return "", fmt.Errorf("git is required to build Go from source: %w", err)
+	}
+
+	ref := opts.Ref
+	if ref == "" {
+		ref = "master"
+	}
+
+	repo, err := prepareRepo(opts.Repo)
+	if err != nil {
+		return "", err
+	}
+
+	commit, err := resolveCommit(repo, ref, opts.Repo)
+	if err != nil {
+		return "", err
+	}
+	name := version.DevelPrefix + commit[:10]
+
+	if _, err := os.Stat(filepath.Join(config.VersionsDir, name)); err == nil {
+		return name, fmt.Errorf("%s (commit %s) is already installed", name, commit)
+	}
+
+	bootstrap, err := findBootstrap(opts.Bootstrap)
+	if err != nil {
+		return "", err
+	}
+
+	cleanStaleStaging()
+
+	stagingPath, err := newStagingDir(name)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stagingPath)
+
+	fmt.Printf("Exporting %s (commit %s)...\n", ref, commit)
+	if err := exportCommit(repo, commit, stagingPath); err != nil {
+		return "", err
+	}
+
+	// Without a .git directory, cmd/dist takes the version from the VERSION file
+	goroot := filepath.Join(stagingPath, "go")
+	versionLine := fmt.Sprintf("devel %s\n", name)
+	if err := os.WriteFile(filepath.Join(goroot, "VERSION"), []byte(versionLine), 0644); err != nil {
+		return "", fmt.Errorf("failed to write VERSION file: %w", err)
+	}
+
+	fmt.Printf("Building %s with GOROOT_BOOTSTRAP=%s...\n", name, bootstrap)
+	build := exec.Command("bash", "make.bash")
+	build.Dir = filepath.Join(goroot, "src")
+	build.Env = append(withoutEnv(os.Environ(), "GOROOT"), "GOROOT_BOOTSTRAP="+bootstrap)
+	build.Stdout = os.Stdout
+	build.Stderr = os.Stderr
+	if err := build.Run(); err != nil {
+		return "", fmt.Errorf("make.bash failed: %w", err)
+	}
+
+	repoName := opts.Repo
+	if repoName == "" {
+		repoName = DefaultGoRepo
+	} else if fi, err := os.Stat(repoName); err == nil && fi.IsDir() {
+		repoName = absPath(repoName)
+	}
+	if err := writeMetadata(stagingPath, metadata.Metadata{
+		Version: name,
+		Method:  metadata.MethodSource,
+		Source:  repoName,
+		Ref:     ref,
+		Commit:  commit,
+	}); err != nil {
+		return "", err
+	}
+
+	if err := commitStagingLocked(stagingPath, name); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// prepareRepo returns the git directory to build from. Local checkouts are used as
+// they are; remote repositories are cloned into (or fetched in) the cache directory.
+func prepareRepo(repo string) (string, error) {
+	if repo != "" {
+		if fi, err := os.Stat(repo); err == nil && fi.IsDir() {
+			return repo, nil
+		}
+	} else {
+		repo = DefaultGoRepo
+	}
+
+	clone := filepath.Join(config.CacheDir, "go-src.git")
+	if _, err := os.Stat(clone); os.IsNotExist(err) {
+		fmt.Printf("Cloning %s (this may take a while)...\n", repo)
+		if err := os.MkdirAll(config.CacheDir, 0755); err != nil {
+			return "", fmt.Errorf("failed to create cache directory: %w", err)
+		}
+		// Clone into a temporary name so an interrupted clone is not mistaken for a complete one
+		tmpClone := clone + ".tmp"
+		_ = os.RemoveAll(tmpClone)
+		if _, err := runGit("", "clone", "--bare", repo, tmpClone); err != nil {
+			_ = os.RemoveAll(tmpClone)
+			return "", err
+		}
+		if err := os.Rename(tmpClone, clone); err != nil {
+			return "", fmt.Errorf("failed to move clone into place: %w", err)
+		}
+		return clone, nil
+	}
+
+	fmt.Printf("Fetching %s...\n", repo)
+	if _, err := runGit(clone, "fetch", "--force", "--tags", repo, "+refs/heads/*:refs/heads/*"); err != nil {
+		return "", err
+	}
+	return clone, nil
+}
+
+// resolveCommit resolves ref to a full commit hash in repo. Refs that are not
+// available locally (e.g. Gerrit change refs) are fetched from remote.
+func resolveCommit(repo, ref, remote string) (string, error) {
+	if out, err := runGit(repo, "rev-parse", "--verify", "--quiet", ref+"^{commit}"); err == nil {
+		return out, nil
+	}
+
+	if remote == "" {
+		remote = DefaultGoRepo
+	}
+	if fi, err := os.Stat(remote); err == nil && fi.IsDir() {
+		return "", fmt.Errorf("unknown git ref %q in %s", ref, repo)
+	}
+
+	if _, err := runGit(repo, "fetch", remote, ref); err != nil {
+		return "", fmt.Errorf("unknown git ref %q: %w", ref, err)
+	}
+	return runGit(repo, "rev-parse", "--verify", "FETCH_HEAD^{commit}")
+}
+
+// exportCommit writes the tree of commit to dest/go using git archive.
+func exportCommit(repo, commit, dest string) error {
+	archive := exec.Command("git", "-C", repo, "archive", "--format=tar", "--prefix=go/", commit)
+	var stderr bytes.Buffer
+	archive.Stderr = &stderr
+	stdout, err := archive.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("failed to run git archive: %w", err)
+	}
+	if err := archive.Start(); err != nil {
+		return fmt.Errorf("failed to run git archive: %w", err)
+	}
+
+	extractErr := extractTar(stdout, dest)
+	// Drain the pipe so git can exit even if extraction stopped early
+	_, _ = io.Copy(io.Discard, stdout)
+	if err := archive.Wait(); err != nil {
+		return fmt.Errorf("git archive failed: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	if extractErr != nil {
+		return fmt.Errorf("failed to extract source tree: %w", extractErr)
+	}
+	return nil
+}
+
+// findBootstrap returns the GOROOT of the installed version used to bootstrap the
+// build: the requested one, or otherwise the newest installed release.
+func findBootstrap(requested string) (string, error) {
+	if requested != "" {
+		if !strings.HasPrefix(requested, "go") {
+			requested = "go" + requested
+		}
+		goroot := filepath.Join(config.VersionsDir, requested, "go")
+		if err := version.ValidateGoRoot(goroot); err != nil {
+			return "", fmt.Errorf("bootstrap version %s is not usable: %w", requested, err)
+		}
+		return goroot, nil
+	}
+
+	localVersions, err := version.GetLocalVersions()
+	if err != nil {
+		return "", err
+	}
+
+	newest := ""
+	for _, v := range localVersions {
+		if version.IsValid(v) && !version.IsPrerelease(v) && (newest == "" || version.Compare(v, newest) > 0) {
+			newest = v
+		}
+	}
+	if newest == "" {
+		return "", fmt.Errorf("no installed Go release to bootstrap the build with; install one first (e.g., 'sgv latest')")
+	}
+	return filepath.Join(config.VersionsDir, newest, "go"), nil
+}
+
+// runGit runs git (in dir, if not empty) and returns its trimmed standard output.
+func runGit(dir string, args ...string) (string, error) {
+	if dir != "" {
+		args = append([]string{"-C", dir}, args...)
+	}
+	cmd := exec.Command("git", append(httpclient.GitConfig(), args...)...)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("git %s failed: %w: %s", strings.Join(args, " "), err, strings.TrimSpace(stderr.String()))
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
+// withoutEnv returns env without the given variable.
+func withoutEnv(env []string, key string) []string {
+	var result []string
+	for _, kv := range env {
+		if !strings.HasPrefix(kv, key+"=") {
+			result = append(result, kv)
+		}
+	}
+	return result
+}
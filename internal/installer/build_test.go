@@ -0,0 +1,134 @@
+package installer
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// fakeMakeBash records GOROOT_BOOTSTRAP and produces a go "binary" like the real make.bash.
+const fakeMakeBash = `#!/bin/bash
+set -e
+mkdir -p ../bin
+echo "$GOROOT_BOOTSTRAP" > ../bin/bootstrap
+printf '#!/bin/sh\necho built\n' > ../bin/go
+chmod +x ../bin/go
+`
+
+// newFakeGoRepo creates a git repository with a src/make.bash script and returns its path and HEAD commit.
+func newFakeGoRepo(t *testing.T) (string, string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	repo := filepath.Join(t.TempDir(), "go")
+	if err := os.MkdirAll(filepath.Join(repo, "src"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(repo, "src", "make.bash"), []byte(fakeMakeBash), 0755); err != nil {
+		t.Fatalf("write make.bash: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"init", "-q", "-b", "master"},
+		{"add", "."},
+		{"-c", "user.name=test", "-c", "user.email=test@example.com", "commit", "-q", "-m", "initial"},
+	} {
+		if _, err := runGit(repo, args...); err != nil {
+			t.Fatalf("%v", err)
+		}
+	}
+
+	commit, err := runGit(repo, "rev-parse", "HEAD")
+	if err != nil {
+		t.Fatalf("%v", err)
+	}
+	return repo, commit
+}
+
+// installFakeRelease creates a minimal installed release usable as bootstrap toolchain.
+func installFakeRelease(t *testing.T, goVersion string) string {
+	t.Helper()
+	goroot := filepath.Join(config.VersionsDir, goVersion, "go")
+	if err := os.MkdirAll(filepath.Join(goroot, "bin"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "bin", "go"), []byte("#!/bin/sh\n"), 0755); err != nil {
+		t.Fatalf("write go binary stub: %v", err)
+	}
+	return goroot
+}
+
+func TestInstallFromSource(t *testing.T) {
+	setupTestDirs(t)
+	repo, commit := newFakeGoRepo(t)
+
+	installFakeRelease(t, "go1.21.13")
+	newest := installFakeRelease(t, "go1.22.6")
+	installFakeRelease(t, "go1.23rc1")
+
+	name, err := InstallFromSource(SourceOptions{Repo: repo})
+	if err != nil {
+		t.Fatalf("InstallFromSource failed: %v", err)
+	}
+	if want := "gotip-" + commit[:10]; name != want {
+		t.Errorf("InstallFromSource() = %q, want %q", name, want)
+	}
+
+	goroot := filepath.Join(config.VersionsDir, name, "go")
+	bootstrap, err := os.ReadFile(filepath.Join(goroot, "bin", "bootstrap"))
+	if err != nil {
+		t.Fatalf("read bootstrap marker: %v", err)
+	}
+	if got := strings.TrimSpace(string(bootstrap)); got != newest {
+		t.Errorf("GOROOT_BOOTSTRAP = %q, want newest installed release %q", got, newest)
+	}
+	versionFile, err := os.ReadFile(filepath.Join(goroot, "VERSION"))
+	if err != nil || !strings.HasPrefix(string(versionFile), "devel "+name) {
+		t.Errorf("unexpected VERSION file %q, %v", versionFile, err)
+	}
+
+	if _, err := InstallFromSource(SourceOptions{Repo: repo, Ref: commit}); err == nil {
+		t.Errorf("expected error when building an already installed commit")
+	}
+}
+
+func TestInstallFromSourceErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		release string
+		opts    func(repo string) SourceOptions
+	}{
+		{"unknown ref", "go1.22.6", func(repo string) SourceOptions {
+			return SourceOptions{Repo: repo, Ref: "no-such-branch"}
+		}},
+		{"no bootstrap toolchain", "", func(repo string) SourceOptions {
+			return SourceOptions{Repo: repo}
+		}},
+		{"missing bootstrap version", "go1.22.6", func(repo string) SourceOptions {
+			return SourceOptions{Repo: repo, Bootstrap: "1.20.1"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setupTestDirs(t)
+			repo, _ := newFakeGoRepo(t)
+			if err := os.MkdirAll(config.VersionsDir, 0755); err != nil {
+				t.Fatalf("mkdir: %v", err)
+			}
+			if tt.release != "" {
+				installFakeRelease(t, tt.release)
+			}
+
+			if _, err := InstallFromSource(tt.opts(repo)); err == nil {
+				t.Errorf("expected InstallFromSource to fail")
+			}
+		})
+	}
+}
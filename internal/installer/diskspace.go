@@ -0,0 +1,50 @@
+package installer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// unpackFactor estimates how much larger an unpacked Go distribution is than its
+// compressed archive (a ~70 MB go1.22 tarball unpacks to ~250 MB).
+const unpackFactor = 4
+
+// requiredSpace estimates the free space needed to install an archive of size
+// bytes: the unpacked distribution plus the archive itself unless it is cached.
+func requiredSpace(size int64, cached bool) int64 {
+	need := size * unpackFactor
+	if !cached {
+		need += size
+	}
+	return need
+}
+
+// checkDiskSpace returns an error if the filesystem holding dir has less than need
+// bytes available. dir does not have to exist yet.
+func checkDiskSpace(dir string, need int64) error {
+	free, err := freeSpace(dir)
+	if err != nil {
+		return err
+	}
+	if free < need {
+		return fmt.Errorf("not enough disk space in %s: about %d MiB needed, %d MiB available", dir, need>>20, free>>20)
+	}
+	return nil
+}
+
+// freeSpace returns the bytes available to unprivileged users on the filesystem
+// holding path, or its nearest existing parent.
+func freeSpace(path string) (int64, error) {
+	for {
+		free, err := availableSpace(path)
+		if err == nil {
+			return free, nil
+		}
+		parent := filepath.Dir(path)
+		if !os.IsNotExist(err) || parent == path {
+			return 0, fmt.Errorf("failed to check free space in %s: %w", path, err)
+		}
+		path = parent
+	}
+}
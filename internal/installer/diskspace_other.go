@@ -0,0 +1,14 @@
+//go:build !unix
+
+package installer
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// availableSpace returns the bytes available to unprivileged users on the
+// filesystem holding path, which must exist.
+func availableSpace(path string) (int64, error) {
+	return 0, fmt.Errorf("free space cannot be checked on %s", runtime.GOOS)
+}
@@ -0,0 +1,30 @@
+package installer
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequiredSpace(t *testing.T) {
+	if got := requiredSpace(100, false); got != 100*unpackFactor+100 {
+		t.Errorf("requiredSpace(100, false) = %d", got)
+	}
+	if got := requiredSpace(100, true); got != 100*unpackFactor {
+		t.Errorf("requiredSpace(100, true) = %d", got)
+	}
+}
+
+func TestCheckDiskSpace(t *testing.T) {
+	// The versions directory may not exist before the first install
+	dir := filepath.Join(t.TempDir(), "versions", "not-created")
+
+	if err := checkDiskSpace(dir, 1); err != nil {
+		t.Errorf("checkDiskSpace for 1 byte failed: %v", err)
+	}
+
+	err := checkDiskSpace(dir, 1<<62)
+	if err == nil || !strings.Contains(err.Error(), "not enough disk space") {
+		t.Errorf("expected a disk space error, got %v", err)
+	}
+}
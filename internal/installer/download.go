@@ -0,0 +1,277 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/source"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+const (
+	initialBackoff = time.Second
+	maxBackoff     = 30 * time.Second
+)
+
+// sleep is replaced in tests to avoid waiting for backoff delays.
+var sleep = time.Sleep
+
+// downloadFile downloads file from src to dest and verifies it against checksum,
+// which is either a hex encoded SHA-256 or a go.sum style "h1:" hash of a module zip.
+//
+// Data is written to dest + ".part" first. A leftover partial file from an earlier
+// run is resumed when the source supports it, and transient failures are retried
+// with exponential backoff up to config.DownloadAttempts times. dest only appears
+// once the checksum matches.
+func downloadFile(src source.Source, file source.File, dest, checksum string, opts Options) error {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return fmt.Errorf("failed to create download directory: %w", err)
+	}
+
+	partPath := dest + ".part"
+	attempts := max(config.DownloadAttempts, 1)
+	backoff := initialBackoff
+
+	var sum []byte
+	for attempt := 1; ; attempt++ {
+		var err error
+		sum, err = downloadAttempt(src, file, partPath, opts)
+		if err == nil {
+			break
+		}
+		if !isTransient(err) || attempt >= attempts {
+			return fmt.Errorf("download failed after %d attempt(s): %w", attempt, err)
+		}
+
+		opts.warnf("download interrupted (attempt %d/%d): %v. Retrying in %s...\n", attempt, attempts, err, backoff)
+		sleep(backoff)
+		backoff = min(backoff*2, maxBackoff)
+	}
+
+	verify := func() error { return verifyChecksum(sum, checksum) }
+	if isModuleHash(checksum) {
+		verify = func() error { return verifyModuleHash(partPath, checksum) }
+	}
+	if err := verify(); err != nil {
+		// A corrupt partial file would fail every future resume, so start over next time
+		_ = os.Remove(partPath)
+		return err
+	}
+
+	if err := os.Rename(partPath, dest); err != nil {
+		return fmt.Errorf("failed to move download into place: %w", err)
+	}
+	return nil
+}
+
+// downloadAttempt appends the remainder of file to partPath and returns the SHA-256 of the complete file.
+func downloadAttempt(src source.Source, file source.File, partPath string, opts Options) ([]byte, error) {
+	out, err := os.OpenFile(partPath, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file %s: %w", partPath, err)
+	}
+	defer out.Close()
+
+	fi, err := out.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat %s: %w", partPath, err)
+	}
+
+	archive, err := src.Open(file, fi.Size())
+	if errors.Is(err, source.ErrRangeNotSatisfiable) {
+		if err := out.Truncate(0); err != nil {
+			return nil, fmt.Errorf("failed to truncate %s: %w", partPath, err)
+		}
+		return nil, err
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer archive.Close()
+
+	hasher := sha256.New()
+	offset := archive.Offset
+	if offset > 0 {
+		// Resume: feed the bytes already on disk into the hash, then append
+		if err := hashExisting(out, hasher, offset); err != nil {
+			return nil, err
+		}
+		opts.printf("Resuming previous download (%d bytes already on disk)\n", offset)
+	} else if err := out.Truncate(0); err != nil {
+		// Either a fresh download or a source that cannot resume: start from scratch
+		return nil, fmt.Errorf("failed to truncate %s: %w", partPath, err)
+	}
+
+	if _, err := out.Seek(offset, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek %s: %w", partPath, err)
+	}
+
+	bar := opts.progress(archive.Size, offset)
+
+	// Hash the stream while writing it so the archive is only read once
+	if _, err := io.Copy(io.MultiWriter(out, bar, hasher), archive); err != nil {
+		return nil, fmt.Errorf("failed to write download to file: %w", err)
+	}
+	if err := out.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close %s: %w", partPath, err)
+	}
+
+	return hasher.Sum(nil), nil
+}
+
+// verifyFile checks that the file at path matches checksum (see downloadFile).
+func verifyFile(path, checksum string) error {
+	if isModuleHash(checksum) {
+		if _, err := os.Stat(path); err != nil {
+			return err
+		}
+		return verifyModuleHash(path, checksum)
+	}
+
+	sum, err := sha256File(path)
+	if err != nil {
+		return err
+	}
+	return verifyChecksum(sum, checksum)
+}
+
+// sha256File returns the SHA-256 digest of the file at path.
+func sha256File(path string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+	return hasher.Sum(nil), nil
+}
+
+// hashExisting feeds the first n bytes of f into h.
+func hashExisting(f *os.File, h hash.Hash, n int64) error {
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to seek partial download: %w", err)
+	}
+	if _, err := io.CopyN(h, f, n); err != nil {
+		return fmt.Errorf("failed to read partial download: %w", err)
+	}
+	return nil
+}
+
+// isTransient reports whether a failed download attempt is worth retrying.
+func isTransient(err error) bool {
+	if errors.Is(err, source.ErrRangeNotSatisfiable) {
+		return true
+	}
+
+	var se *source.StatusError
+	if errors.As(err, &se) {
+		return se.Code >= 500 || se.Code == http.StatusTooManyRequests || se.Code == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		strings.Contains(err.Error(), "connection reset")
+}
+
+// DownloadArchive downloads a file listed in the release index to dest, verifying
+// its checksum. If dest already holds a verified copy, nothing is downloaded. It
+// returns the hex SHA-256 digest of the verified copy, which may come from a
+// checksum sidecar rather than the index.
+func DownloadArchive(file version.GoVersionFile, dest string) (string, error) {
+	sources, err := source.Configured()
+	if err != nil {
+		return "", err
+	}
+
+	f, err := fetchArchive(sources, file, func(string) (string, error) { return dest, nil }, Options{})
+	if err != nil {
+		return "", err
+	}
+	if !isModuleHash(f.checksum) {
+		return strings.ToLower(f.checksum), nil
+	}
+	// Module zips are verified by their go.sum hash, which is no SHA-256 digest
+	sum, err := sha256File(f.path)
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(sum), nil
+}
+
+// fetched describes an archive made available by fetchArchive.
+type fetched struct {
+	path     string
+	checksum string        // The checksum the archive was verified against
+	source   source.Source // The source that served the archive or, if cached, provided its checksum
+	cached   bool          // Whether a verified copy already existed
+}
+
+// fetchArchive makes a verified copy of file available at dest(checksum). The
+// sources are tried in order, moving on to the next one when a source fails
+// (connection errors, bad HTTP statuses or checksum mismatches).
+func fetchArchive(sources []source.Source, file source.File, dest func(checksum string) (string, error), opts Options) (fetched, error) {
+	var errs []error
+	for i, src := range sources {
+		f, err := fetchFrom(src, file, dest, opts)
+		if err == nil {
+			return f, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
+
+		if i < len(sources)-1 {
+			opts.warnf("source %s failed: %v. Trying next source...\n", src.Name(), err)
+		}
+	}
+
+	if len(errs) == 0 {
+		return fetched{}, fmt.Errorf("no download source configured")
+	}
+	return fetched{}, errors.Join(errs...)
+}
+
+// fetchFrom downloads file from src unless a verified copy already exists at dest(checksum).
+func fetchFrom(src source.Source, file source.File, dest func(checksum string) (string, error), opts Options) (fetched, error) {
+	checksum, err := src.Checksum(file)
+	if err != nil {
+		return fetched{}, err
+	}
+	path, err := dest(checksum)
+	if err != nil {
+		return fetched{}, err
+	}
+
+	f := fetched{path: path, checksum: checksum, source: src}
+	if err := verifyFile(f.path, checksum); err == nil {
+		f.cached = true
+		return f, nil
+	} else if !os.IsNotExist(err) {
+		opts.warnf("discarding existing file %s: %v\n", f.path, err)
+		_ = os.Remove(f.path)
+	}
+
+	opts.printf("Downloading %s from %s\n", file.Filename, src.Name())
+	if err := downloadFile(src, file, f.path, checksum, opts); err != nil {
+		return fetched{}, err
+	}
+	return f, nil
+}
@@ -0,0 +1,196 @@
+package installer
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/source"
+)
+
+// setupDownloadTest disables backoff delays and returns a payload with its checksum.
+func setupDownloadTest(t *testing.T, attempts int) ([]byte, string) {
+	t.Helper()
+
+	originalAttempts, originalSleep := config.DownloadAttempts, sleep
+	config.DownloadAttempts = attempts
+	sleep = func(time.Duration) {}
+	t.Cleanup(func() {
+		config.DownloadAttempts, sleep = originalAttempts, originalSleep
+	})
+
+	payload := bytes.Repeat([]byte("0123456789abcdef"), 4096)
+	sum := sha256.Sum256(payload)
+	return payload, hex.EncodeToString(sum[:])
+}
+
+// testArchive is the file downloaded by the tests; servers answer any path.
+var testArchive = source.File{Filename: "archive.tar.gz"}
+
+func serveContent(payload []byte) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "archive.tar.gz", time.Time{}, bytes.NewReader(payload))
+	}
+}
+
+func TestDownloadFileResumesPartialDownload(t *testing.T) {
+	payload, checksum := setupDownloadTest(t, 1)
+
+	var rangeHeader atomic.Value
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rangeHeader.Store(r.Header.Get("Range"))
+		serveContent(payload)(w, r)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+	half := len(payload) / 2
+	if err := os.WriteFile(dest+".part", payload[:half], 0644); err != nil {
+		t.Fatalf("write partial file: %v", err)
+	}
+
+	if err := downloadFile(source.NewGoDev(server.URL), testArchive, dest, checksum, Options{}); err != nil {
+		t.Fatalf("downloadFile failed: %v", err)
+	}
+
+	if got := rangeHeader.Load(); got != "bytes=32768-" {
+		t.Errorf("unexpected Range header %q", got)
+	}
+	got, err := os.ReadFile(dest)
+	if err != nil {
+		t.Fatalf("read download: %v", err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("downloaded content differs from payload")
+	}
+	if _, err := os.Stat(dest + ".part"); !os.IsNotExist(err) {
+		t.Errorf("expected partial file to be gone, got: %v", err)
+	}
+}
+
+func TestDownloadFileRetriesTransientFailures(t *testing.T) {
+	payload, checksum := setupDownloadTest(t, 3)
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) < 3 {
+			http.Error(w, "try again", http.StatusServiceUnavailable)
+			return
+		}
+		serveContent(payload)(w, r)
+	}))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+	if err := downloadFile(source.NewGoDev(server.URL), testArchive, dest, checksum, Options{}); err != nil {
+		t.Fatalf("downloadFile failed: %v", err)
+	}
+	if n := requests.Load(); n != 3 {
+		t.Errorf("expected 3 requests, got %d", n)
+	}
+}
+
+func TestDownloadFileGivesUp(t *testing.T) {
+	tests := []struct {
+		name         string
+		status       int
+		attempts     int
+		wantRequests int32
+	}{
+		{"not found is not retried", http.StatusNotFound, 5, 1},
+		{"server errors exhaust attempts", http.StatusBadGateway, 4, 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, checksum := setupDownloadTest(t, tt.attempts)
+
+			var requests atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests.Add(1)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+			if err := downloadFile(source.NewGoDev(server.URL), testArchive, dest, checksum, Options{}); err == nil {
+				t.Fatalf("expected download to fail")
+			}
+			if n := requests.Load(); n != tt.wantRequests {
+				t.Errorf("expected %d requests, got %d", tt.wantRequests, n)
+			}
+			if _, err := os.Stat(dest); !os.IsNotExist(err) {
+				t.Errorf("expected no destination file, got: %v", err)
+			}
+		})
+	}
+}
+
+func TestDownloadFileChecksumMismatch(t *testing.T) {
+	payload, _ := setupDownloadTest(t, 1)
+	other := sha256.Sum256([]byte("something else"))
+
+	server := httptest.NewServer(serveContent(payload))
+	defer server.Close()
+
+	dest := filepath.Join(t.TempDir(), "archive.tar.gz")
+	if err := downloadFile(source.NewGoDev(server.URL), testArchive, dest, hex.EncodeToString(other[:]), Options{}); err == nil {
+		t.Fatalf("expected checksum mismatch error")
+	}
+	for _, p := range []string{dest, dest + ".part"} {
+		if _, err := os.Stat(p); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got: %v", p, err)
+		}
+	}
+}
+
+func TestFetchArchiveFailsOver(t *testing.T) {
+	payload, checksum := setupDownloadTest(t, 1)
+
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	}))
+	defer down.Close()
+	tampered := httptest.NewServer(serveContent([]byte("tampered archive")))
+	defer tampered.Close()
+	good := httptest.NewServer(serveContent(payload))
+	defer good.Close()
+
+	original := config.DownloadMirrors
+	config.DownloadMirrors = []string{down.URL + "/", tampered.URL + "/", good.URL + "/"}
+	t.Cleanup(func() { config.DownloadMirrors = original })
+
+	file := source.File{Filename: "go1.22.1.linux-amd64.tar.gz", SHA256: checksum}
+	dest := filepath.Join(t.TempDir(), file.Filename)
+	sources, err := source.Parse("godev")
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	f, err := fetchArchive(sources, file, func(string) (string, error) { return dest, nil }, Options{})
+	if err != nil {
+		t.Fatalf("fetchArchive failed: %v", err)
+	}
+	if f.cached || f.source.Name() != good.URL+"/" || f.checksum != checksum {
+		t.Errorf("got %+v, want a download from %q", f, good.URL+"/")
+	}
+	if got, _ := os.ReadFile(f.path); !bytes.Equal(got, payload) {
+		t.Errorf("downloaded content differs from payload")
+	}
+
+	// A verified copy is not downloaded again
+	if f, err := fetchArchive(sources[:1], file, func(string) (string, error) { return dest, nil }, Options{}); err != nil || !f.cached {
+		t.Errorf("expected existing copy to be reused, got %+v, error %v", f, err)
+	}
+
+	if _, err := fetchArchive(sources[:1], file, func(string) (string, error) { return dest + ".2", nil }, Options{}); err == nil {
+		t.Errorf("expected error when every source fails")
+	}
+}
@@ -0,0 +1,8 @@
+//go:build !linux && !darwin
+
+package installer
+
+// exchange swaps the directories a and b.
+func exchange(a, b string) error {
+	return exchangeByRename(a, b)
+}
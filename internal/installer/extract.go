@@ -0,0 +1,371 @@
+package installer
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// extractTarGz extracts a .tar.gz archive to the specified destination.
+//
+// Archives may come from third-party mirrors, so every entry is confined to dest:
+// entries whose path escapes dest, symlinks pointing outside of it, and hard links
+// to files outside of it are rejected.
+func extractTarGz(src, dest string) error {
+	file, err := os.Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer file.Close()
+
+	gzipReader, err := gzip.NewReader(file)
+	if err != nil {
+		return fmt.Errorf("failed to create gzip reader: %w", err)
+	}
+	defer gzipReader.Close()
+
+	return extractTar(gzipReader, dest)
+}
+
+// extractTar extracts an uncompressed tar stream to the specified destination,
+// with the same safety checks as extractTarGz.
+func extractTar(r io.Reader, dest string) error {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination %s: %w", dest, err)
+	}
+
+	tarReader := tar.NewReader(r)
+
+	// Directory mtimes are applied last, since creating their children updates them
+	type dirTime struct {
+		path    string
+		modTime time.Time
+	}
+	var dirTimes []dirTime
+
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			break // End of archive
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar header: %w", err)
+		}
+
+		if header.Typeflag == tar.TypeXGlobalHeader {
+			continue // PAX global headers carry no file
+		}
+
+		targetPath, err := resolveEntryPath(dest, header.Name)
+		if err != nil {
+			return err
+		}
+		if err := checkNoSymlinkParents(dest, targetPath); err != nil {
+			return err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(targetPath, 0755); err != nil {
+				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
+			}
+			dirTimes = append(dirTimes, dirTime{targetPath, header.ModTime})
+		case tar.TypeReg:
+			// Ensure parent directory exists
+			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+				return fmt.Errorf("failed to create parent directory for %s: %w", targetPath, err)
+			}
+			// Never write through whatever currently occupies the path
+			if err := removeExisting(targetPath); err != nil {
+				return err
+			}
+			outFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+			if err != nil {
+				return fmt.Errorf("failed to create file %s: %w", targetPath, err)
+			}
+			// Close the file immediately after writing to release the file descriptor
+			// This is crucial for handling a large number of files and preventing "too many open files" errors.
+			if _, err := io.Copy(outFile, tarReader); err != nil {
+				outFile.Close() // Close on error
+				return fmt.Errorf("failed to write file %s: %w", targetPath, err)
+			}
+			outFile.Close()
+			// Set file permissions
+			if err := os.Chmod(targetPath, header.FileInfo().Mode().Perm()); err != nil {
+				return fmt.Errorf("failed to set file permissions for %s: %w", targetPath, err)
+			}
+			if err := os.Chtimes(targetPath, header.ModTime, header.ModTime); err != nil {
+				return fmt.Errorf("failed to set modification time for %s: %w", targetPath, err)
+			}
+		case tar.TypeSymlink:
+			if err := checkSymlinkTarget(dest, targetPath, header.Linkname); err != nil {
+				return fmt.Errorf("refusing to create symlink %s: %w", header.Name, err)
+			}
+			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+				return fmt.Errorf("failed to create parent directory for %s: %w", targetPath, err)
+			}
+			if err := removeExisting(targetPath); err != nil {
+				return err
+			}
+			if err := os.Symlink(header.Linkname, targetPath); err != nil {
+				return fmt.Errorf("failed to create symlink %s: %w", targetPath, err)
+			}
+		case tar.TypeLink:
+			// Hard link names are relative to the archive root, not to the entry
+			linkTarget, err := resolveEntryPath(dest, header.Linkname)
+			if err != nil {
+				return fmt.Errorf("refusing to create hard link %s: %w", header.Name, err)
+			}
+			if err := checkNoSymlinkParents(dest, linkTarget); err != nil {
+				return fmt.Errorf("refusing to create hard link %s: %w", header.Name, err)
+			}
+			if fi, err := os.Lstat(linkTarget); err != nil {
+				return fmt.Errorf("hard link %s points to missing entry %s: %w", header.Name, header.Linkname, err)
+			} else if !fi.Mode().IsRegular() {
+				return fmt.Errorf("hard link %s points to non-regular file %s", header.Name, header.Linkname)
+			}
+			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+				return fmt.Errorf("failed to create parent directory for %s: %w", targetPath, err)
+			}
+			if err := removeExisting(targetPath); err != nil {
+				return err
+			}
+			if err := os.Link(linkTarget, targetPath); err != nil {
+				return fmt.Errorf("failed to create hard link %s: %w", targetPath, err)
+			}
+		case tar.TypeChar, tar.TypeBlock, tar.TypeFifo:
+			// Device nodes and FIFOs have no place in a Go distribution; skip them
+			continue
+		default:
+			return fmt.Errorf("unsupported tar entry type: %v in %s", header.Typeflag, header.Name)
+		}
+	}
+
+	for i := len(dirTimes) - 1; i >= 0; i-- {
+		if err := os.Chtimes(dirTimes[i].path, dirTimes[i].modTime, dirTimes[i].modTime); err != nil {
+			return fmt.Errorf("failed to set modification time for %s: %w", dirTimes[i].path, err)
+		}
+	}
+
+	return nil
+}
+
+// extractModuleZip extracts a module zip to dest, stripping the "<module>@<version>/"
+// prefix every entry carries (or "go/" for the Windows zips from go.dev). Module zips
+// hold only regular files and record no permissions, so like the go command does for
+// toolchains, files below bin/ and pkg/tool/ are made executable.
+func extractModuleZip(src, dest, prefix string) error {
+	dest, err := filepath.Abs(dest)
+	if err != nil {
+		return fmt.Errorf("failed to resolve destination %s: %w", dest, err)
+	}
+
+	zr, err := zip.OpenReader(src)
+	if err != nil {
+		return fmt.Errorf("failed to open archive: %w", err)
+	}
+	defer zr.Close()
+
+	for _, f := range zr.File {
+		name, ok := strings.CutPrefix(f.Name, prefix)
+		if !ok {
+			return fmt.Errorf("archive entry %q is outside of %s", f.Name, prefix)
+		}
+		if name == "" || strings.HasSuffix(name, "/") {
+			continue // Directories are created on demand
+		}
+
+		targetPath, err := resolveEntryPath(dest, name)
+		if err != nil {
+			return err
+		}
+
+		perm := os.FileMode(0644)
+		if strings.HasPrefix(name, "bin/") || strings.HasPrefix(name, "pkg/tool/") {
+			perm = 0755
+		}
+		if err := extractZipFile(f, targetPath, perm); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// extractZipFile writes a single zip entry to targetPath.
+func extractZipFile(f *zip.File, targetPath string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
+		return fmt.Errorf("failed to create directory for %s: %w", targetPath, err)
+	}
+
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open archive entry %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	outFile, err := os.OpenFile(targetPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, perm)
+	if err != nil {
+		return fmt.Errorf("failed to create file %s: %w", targetPath, err)
+	}
+	if _, err := io.Copy(outFile, rc); err != nil {
+		outFile.Close()
+		return fmt.Errorf("failed to write file %s: %w", targetPath, err)
+	}
+	if err := outFile.Close(); err != nil {
+		return fmt.Errorf("failed to close file %s: %w", targetPath, err)
+	}
+
+	if !f.Modified.IsZero() {
+		if err := os.Chtimes(targetPath, f.Modified, f.Modified); err != nil {
+			return fmt.Errorf("failed to set modification time for %s: %w", targetPath, err)
+		}
+	}
+	return nil
+}
+
+// resolveEntryPath joins an archive entry name onto dest, rejecting names that would escape it.
+func resolveEntryPath(dest, name string) (string, error) {
+	if filepath.IsAbs(name) {
+		return "", fmt.Errorf("archive entry %q has an absolute path", name)
+	}
+	targetPath := filepath.Join(dest, name)
+	if !isWithin(dest, targetPath) {
+		return "", fmt.Errorf("archive entry %q escapes the destination directory", name)
+	}
+	return targetPath, nil
+}
+
+// checkSymlinkTarget ensures that a symlink created at linkPath pointing to target stays inside dest.
+func checkSymlinkTarget(dest, linkPath, target string) error {
+	if target == "" {
+		return fmt.Errorf("empty link target")
+	}
+	if filepath.IsAbs(target) {
+		return fmt.Errorf("absolute link target %q", target)
+	}
+	hops := 0
+	if _, err := resolveLinkTarget(dest, filepath.Dir(linkPath), target, &hops); err != nil {
+		return fmt.Errorf("link target %q %w", target, err)
+	}
+	return nil
+}
+
+// maxSymlinkHops bounds the links followed to resolve a symlink target, as the
+// kernel's limit does.
+const maxSymlinkHops = 40
+
+// resolveLinkTarget resolves target relative to dir the way the kernel will, following
+// the symlinks already extracted below dest, and fails if any step leaves dest. A
+// lexical check is not enough: with "a/s -> .." in place, "a/t -> s/.." looks like
+// it stays in a/ but really points above it. Components that don't exist yet may
+// later become symlinks, so no ".." may follow them. hops counts the links followed.
+func resolveLinkTarget(dest, dir, target string, hops *int) (string, error) {
+	current, missing := dir, false
+	for _, part := range strings.Split(filepath.ToSlash(target), "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if missing {
+				return "", fmt.Errorf("leaves a directory that does not exist yet")
+			}
+			current = filepath.Dir(current)
+			if !isWithin(dest, current) {
+				return "", fmt.Errorf("escapes the destination directory")
+			}
+			continue
+		}
+
+		current = filepath.Join(current, part)
+		if missing {
+			continue
+		}
+		fi, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			missing = true
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("cannot be resolved: %w", err)
+		}
+		if fi.Mode()&os.ModeSymlink == 0 {
+			continue
+		}
+
+		if *hops++; *hops > maxSymlinkHops {
+			return "", fmt.Errorf("passes through too many symlinks")
+		}
+		link, err := os.Readlink(current)
+		if err != nil {
+			return "", fmt.Errorf("cannot be resolved: %w", err)
+		}
+		if filepath.IsAbs(link) {
+			return "", fmt.Errorf("passes through absolute symlink %s", current)
+		}
+		if current, err = resolveLinkTarget(dest, filepath.Dir(current), link, hops); err != nil {
+			return "", err
+		}
+	}
+	return current, nil
+}
+
+// checkNoSymlinkParents rejects paths that would be reached through a symlink created
+// by an earlier entry; a later entry could otherwise replace what the symlink
+// points to, or write through it into a place its target check never covered.
+func checkNoSymlinkParents(dest, targetPath string) error {
+	rel, err := filepath.Rel(dest, filepath.Dir(targetPath))
+	if err != nil || rel == "." {
+		return err
+	}
+
+	current := dest
+	for _, part := range strings.Split(rel, string(os.PathSeparator)) {
+		current = filepath.Join(current, part)
+		fi, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil // The rest of the path will be created as plain directories
+		}
+		if err != nil {
+			return fmt.Errorf("failed to stat %s: %w", current, err)
+		}
+		if fi.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("archive entry %s is located below symlink %s", targetPath, current)
+		}
+	}
+	return nil
+}
+
+// isWithin reports whether path is dest itself or lies below it. Both must be clean.
+func isWithin(dest, path string) bool {
+	rel, err := filepath.Rel(dest, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
+
+// removeExisting removes a file or symlink left at path by an earlier entry of the same name.
+func removeExisting(path string) error {
+	fi, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if fi.IsDir() {
+		return fmt.Errorf("cannot replace directory %s with a non-directory entry", path)
+	}
+	if err := os.Remove(path); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,255 @@
+package installer
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// tarEntry describes one entry of a crafted test archive.
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	linkname string
+	mode     int64
+}
+
+// writeTarGz writes entries into a .tar.gz file inside dir and returns its path.
+func writeTarGz(t *testing.T, dir string, entries []tarEntry, modTime time.Time) string {
+	t.Helper()
+
+	path := filepath.Join(dir, "archive.tar.gz")
+	f, err := os.Create(path)
+	if err != nil {
+		t.Fatalf("create archive: %v", err)
+	}
+	defer f.Close()
+
+	gw := gzip.NewWriter(f)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		mode := e.mode
+		if mode == 0 {
+			mode = 0644
+			if e.typeflag == tar.TypeDir {
+				mode = 0755
+			}
+		}
+		hdr := &tar.Header{
+			Name:     e.name,
+			Typeflag: e.typeflag,
+			Linkname: e.linkname,
+			Mode:     mode,
+			Size:     int64(len(e.body)),
+			ModTime:  modTime,
+		}
+		if e.typeflag != tar.TypeReg {
+			hdr.Size = 0
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatalf("write header %s: %v", e.name, err)
+		}
+		if hdr.Size > 0 {
+			if _, err := tw.Write([]byte(e.body)); err != nil {
+				t.Fatalf("write body %s: %v", e.name, err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("close tar writer: %v", err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatalf("close gzip writer: %v", err)
+	}
+	return path
+}
+
+func TestExtractTarGz(t *testing.T) {
+	modTime := time.Date(2024, 2, 6, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr bool
+		check   func(t *testing.T, dest string)
+	}{
+		{
+			name: "regular layout",
+			entries: []tarEntry{
+				{name: "go/", typeflag: tar.TypeDir},
+				{name: "go/bin/", typeflag: tar.TypeDir},
+				{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755},
+				{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1"},
+			},
+			check: func(t *testing.T, dest string) {
+				fi, err := os.Stat(filepath.Join(dest, "go", "bin", "go"))
+				if err != nil {
+					t.Fatalf("stat go binary: %v", err)
+				}
+				if fi.Mode().Perm() != 0755 {
+					t.Errorf("unexpected mode %v", fi.Mode().Perm())
+				}
+			},
+		},
+		{
+			name: "mtimes preserved",
+			entries: []tarEntry{
+				{name: "go/", typeflag: tar.TypeDir},
+				{name: "go/src/", typeflag: tar.TypeDir},
+				{name: "go/src/main.go", typeflag: tar.TypeReg, body: "package main"},
+			},
+			check: func(t *testing.T, dest string) {
+				for _, p := range []string{"go", "go/src", "go/src/main.go"} {
+					fi, err := os.Stat(filepath.Join(dest, p))
+					if err != nil {
+						t.Fatalf("stat %s: %v", p, err)
+					}
+					if !fi.ModTime().Equal(modTime) {
+						t.Errorf("%s: mtime %v, want %v", p, fi.ModTime(), modTime)
+					}
+				}
+			},
+		},
+		{
+			name: "symlink inside tree",
+			entries: []tarEntry{
+				{name: "go/lib/real.txt", typeflag: tar.TypeReg, body: "data"},
+				{name: "go/misc/link.txt", typeflag: tar.TypeSymlink, linkname: "../lib/real.txt"},
+			},
+			check: func(t *testing.T, dest string) {
+				b, err := os.ReadFile(filepath.Join(dest, "go", "misc", "link.txt"))
+				if err != nil || string(b) != "data" {
+					t.Errorf("read through symlink = %q, %v", b, err)
+				}
+			},
+		},
+		{
+			name: "hard link",
+			entries: []tarEntry{
+				{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755},
+				{name: "go/pkg/tool/go", typeflag: tar.TypeLink, linkname: "go/bin/go"},
+			},
+			check: func(t *testing.T, dest string) {
+				a, errA := os.Stat(filepath.Join(dest, "go", "bin", "go"))
+				b, errB := os.Stat(filepath.Join(dest, "go", "pkg", "tool", "go"))
+				if errA != nil || errB != nil {
+					t.Fatalf("stat: %v, %v", errA, errB)
+				}
+				if !os.SameFile(a, b) {
+					t.Errorf("expected hard link to share the same file")
+				}
+			},
+		},
+		{
+			name: "fifo skipped",
+			entries: []tarEntry{
+				{name: "go/fifo", typeflag: tar.TypeFifo},
+				{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1"},
+			},
+			check: func(t *testing.T, dest string) {
+				if _, err := os.Lstat(filepath.Join(dest, "go", "fifo")); !os.IsNotExist(err) {
+					t.Errorf("expected fifo to be skipped, got: %v", err)
+				}
+			},
+		},
+		{
+			name:    "path traversal",
+			entries: []tarEntry{{name: "../evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "nested path traversal",
+			entries: []tarEntry{{name: "go/../../evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute path",
+			entries: []tarEntry{{name: "/tmp/evil.txt", typeflag: tar.TypeReg, body: "x"}},
+			wantErr: true,
+		},
+		{
+			name:    "symlink escaping tree",
+			entries: []tarEntry{{name: "go/escape", typeflag: tar.TypeSymlink, linkname: "../../outside"}},
+			wantErr: true,
+		},
+		{
+			name:    "absolute symlink",
+			entries: []tarEntry{{name: "go/passwd", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}},
+			wantErr: true,
+		},
+		{
+			name:    "hard link escaping tree",
+			entries: []tarEntry{{name: "go/passwd", typeflag: tar.TypeLink, linkname: "../outside.txt"}},
+			wantErr: true,
+		},
+		{
+			name: "write through symlinked directory",
+			entries: []tarEntry{
+				{name: "go/", typeflag: tar.TypeDir},
+				{name: "go/self", typeflag: tar.TypeSymlink, linkname: "."},
+				{name: "go/self/escape", typeflag: tar.TypeSymlink, linkname: "../../outside"},
+			},
+			wantErr: true,
+		},
+		{
+			name: "chained symlinks escaping tree",
+			entries: []tarEntry{
+				{name: "go/a/b/", typeflag: tar.TypeDir},
+				{name: "go/a/b/s", typeflag: tar.TypeSymlink, linkname: "../.."},
+				{name: "go/a/b/t", typeflag: tar.TypeSymlink, linkname: "s/../.."},
+			},
+			wantErr: true,
+		},
+		{
+			name: "symlink leaving a directory created later",
+			entries: []tarEntry{
+				{name: "go/a/", typeflag: tar.TypeDir},
+				{name: "go/a/t", typeflag: tar.TypeSymlink, linkname: "s/../.."},
+				{name: "go/a/s", typeflag: tar.TypeSymlink, linkname: ".."},
+			},
+			wantErr: true,
+		},
+		{
+			name: "chained symlinks inside tree",
+			entries: []tarEntry{
+				{name: "go/lib/real.txt", typeflag: tar.TypeReg, body: "data"},
+				{name: "go/a/b/", typeflag: tar.TypeDir},
+				{name: "go/a/b/s", typeflag: tar.TypeSymlink, linkname: "../.."},
+				{name: "go/a/b/t", typeflag: tar.TypeSymlink, linkname: "s/lib/real.txt"},
+			},
+			check: func(t *testing.T, dest string) {
+				b, err := os.ReadFile(filepath.Join(dest, "go", "a", "b", "t"))
+				if err != nil || string(b) != "data" {
+					t.Errorf("read through chained symlinks = %q, %v", b, err)
+				}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmp := t.TempDir()
+			dest := filepath.Join(tmp, "dest")
+			outside := filepath.Join(tmp, "outside.txt")
+			if err := os.WriteFile(outside, []byte("outside"), 0644); err != nil {
+				t.Fatalf("write outside file: %v", err)
+			}
+
+			archive := writeTarGz(t, tmp, tt.entries, modTime)
+			err := extractTarGz(archive, dest)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("extractTarGz() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if _, err := os.Stat(filepath.Join(tmp, "evil.txt")); !os.IsNotExist(err) {
+				t.Fatalf("file written outside destination")
+			}
+			if tt.check != nil {
+				tt.check(t, dest)
+			}
+		})
+	}
+}
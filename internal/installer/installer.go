@@ -1,144 +1,317 @@
 package installer
 
 import (
-	"archive/tar"
-	"compress/gzip"
+	"encoding/hex"
 	"fmt"
 	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime"
+	"strings"
 
+	"github.com/fun7257/sgv/internal/cache"
 	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/dedupe"
+	"github.com/fun7257/sgv/internal/lock"
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/source"
+	"github.com/fun7257/sgv/internal/version"
 
 	"github.com/schollz/progressbar/v3"
 )
 
+// Options controls where an installation comes from and how it reports progress.
+// The zero value installs from the configured sources and prints to the terminal.
+type Options struct {
+	// Output receives status messages and warnings (default: stdout and stderr).
+	Output io.Writer
+	// Progress returns the writer downloaded data is copied to, for a download of
+	// size bytes (-1 if unknown) resuming at offset (default: a progress bar).
+	Progress func(size, offset int64) io.Writer
+	// GoProxy installs the golang.org/toolchain module from config.GoProxy instead
+	// of using the configured sources.
+	GoProxy bool
+	// Dedupe hard links the new installation's files to identical files of installed
+	// patch releases of the same minor version. config.Dedupe enables it for every install.
+	Dedupe bool
+}
+
+func (o Options) printf(format string, args ...any) {
+	w := o.Output
+	if w == nil {
+		w = os.Stdout
+	}
+	fmt.Fprintf(w, format, args...)
+}
+
+func (o Options) warnf(format string, args ...any) {
+	w := o.Output
+	if w == nil {
+		w = os.Stderr
+	}
+	fmt.Fprintf(w, "Warning: "+format, args...)
+}
+
+// lockVersion takes the install lock of goVersion, reporting a wait to Output so
+// that it doesn't garble the progress bars of concurrent installs.
+func (o Options) lockVersion(goVersion string) (*lock.Lock, error) {
+	w := o.Output
+	if w == nil {
+		w = os.Stderr
+	}
+	return lock.VersionTo(goVersion, w)
+}
+
+func (o Options) progress(size, offset int64) io.Writer {
+	if o.Progress != nil {
+		return o.Progress(size, offset)
+	}
+	bar := progressbar.DefaultBytes(size, "downloading")
+	_ = bar.Set64(offset)
+	return bar
+}
+
 // Install downloads and installs the specified Go version.
-func Install(version string) error {
-	goOS := runtime.GOOS
-	goARCH := runtime.GOARCH
+func Install(goVersion string) error {
+	return InstallWith(goVersion, Options{})
+}
+
+// target describes the platform to install for and where the installation goes.
+type target struct {
+	goos, goarch string
+	dir          string // Directory receiving the installation, checked for free space
+	commit       func(stagingPath, goVersion string) error
+	registered   bool // Installed under VersionsDir, so it can be switched to and deduplicated
+}
+
+// versionsTarget installs for the running platform under VersionsDir with commit.
+func versionsTarget(commit func(stagingPath, goVersion string) error) target {
+	return target{
+		goos:       runtime.GOOS,
+		goarch:     runtime.GOARCH,
+		dir:        config.VersionsDir,
+		commit:     commit,
+		registered: true,
+	}
+}
 
+// InstallWith downloads and installs the specified Go version as configured by
+// opts. It is safe to install different versions concurrently.
+func InstallWith(goVersion string, opts Options) error {
 	// Check if the current platform is supported
-	if goOS == "windows" {
+	if runtime.GOOS == "windows" {
 		return fmt.Errorf("Windows is not supported by sgv. This tool only works on macOS and Linux")
 	}
 
-	filename := fmt.Sprintf("%s.%s-%s.tar.gz", version, goOS, goARCH)
-	downloadURL := fmt.Sprintf("%s%s", config.DownloadURLPrefix, filename)
+	// Serialize with other sgv processes installing the same version
+	l, err := opts.lockVersion(goVersion)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+	if _, err := os.Stat(filepath.Join(config.VersionsDir, goVersion)); err == nil {
+		opts.printf("Go version %s was installed by another sgv process.\n", goVersion)
+		return nil
+	}
 
-	fmt.Printf("Downloading %s from %s\n", version, downloadURL)
+	return install(goVersion, opts, versionsTarget(commitStaging))
+}
 
-	// Create the file to save the download
-	outFilePath := filepath.Join(os.TempDir(), filename)
-	out, err := os.Create(outFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to create file %s: %w", outFilePath, err)
+// Reinstall downloads goVersion again (unless a verified archive is cached) and
+// replaces its installation, even if it is the active version. Env files are kept.
+func Reinstall(goVersion string, opts Options) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("Windows is not supported by sgv. This tool only works on macOS and Linux")
+	}
+	if version.IsDevel(goVersion) {
+		return fmt.Errorf("%s was built from source and cannot be downloaded again; build it with 'sgv install --source' instead", goVersion)
 	}
-	defer out.Close()
 
-	// Download the file with a progress bar
-	req, err := http.NewRequest("GET", downloadURL, nil)
+	l, err := opts.lockVersion(goVersion)
 	if err != nil {
-		return fmt.Errorf("failed to create HTTP request: %w", err)
+		return err
 	}
+	defer l.Release()
 
-	resp, err := http.DefaultClient.Do(req)
+	return install(goVersion, opts, versionsTarget(replaceStaging))
+}
+
+// bundleDir returns the directory InstallTo unpacks goVersion for goos/goarch into.
+func bundleDir(dest, goVersion, goos, goarch string) string {
+	return filepath.Join(dest, fmt.Sprintf("%s.%s-%s", goVersion, goos, goarch))
+}
+
+// InstallTo downloads goVersion for goos/goarch, which may differ from the running
+// platform, and unpacks it into bundleDir(dest, ...) with the same layout as an
+// installed version (go/, manifest and install.json). The result is not registered
+// with sgv and cannot be switched to; it is meant for offline bundles and container
+// build contexts. The directory is returned.
+func InstallTo(goVersion, goos, goarch, dest string, opts Options) (string, error) {
+	if runtime.GOOS == "windows" {
+		return "", fmt.Errorf("Windows is not supported by sgv. This tool only works on macOS and Linux")
+	}
+
+	dir := bundleDir(dest, goVersion, goos, goarch)
+	if _, err := os.Lstat(dir); err == nil {
+		return "", fmt.Errorf("%s already exists", dir)
+	}
+
+	t := target{
+		goos:   goos,
+		goarch: goarch,
+		dir:    dest,
+		commit: func(stagingPath, goVersion string) error {
+			return commitBundle(stagingPath, goVersion, goos, dir)
+		},
+	}
+	if err := install(goVersion, opts, t); err != nil {
+		return "", err
+	}
+	return dir, nil
+}
+
+// install fetches goVersion for the platform of t and hands the staged installation
+// to t.commit. For registered targets the caller must hold the version's install lock.
+func install(goVersion string, opts Options, t target) error {
+	// Remove leftovers of installs that were interrupted before they could clean up
+	cleanStaleStaging()
+
+	if opts.GoProxy {
+		if err := checkToolchainVersion(goVersion); err != nil {
+			return err
+		}
+		file := source.ToolchainFile(goVersion, t.goos, t.goarch)
+		return installArchive([]source.Source{source.NewGoProxy(config.GoProxy)}, file, goVersion, opts, t)
+	}
+
+	archive, err := version.LookupArchive(goVersion, t.goos, t.goarch)
 	if err != nil {
-		return fmt.Errorf("failed to download file: %w", err)
+		return fmt.Errorf("failed to look up %s: %w", goVersion, err)
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("bad HTTP status: %s", resp.Status)
+	sources, err := source.Configured()
+	if err != nil {
+		return err
 	}
+	return installArchive(sources, archive, goVersion, opts, t)
+}
 
-	bar := progressbar.DefaultBytes(
-		resp.ContentLength,
-		"downloading",
-	)
+// installArchive fetches file from the first source that can serve it and installs
+// it as goVersion into t. Archives are kept in the download cache, so reinstalling
+// a removed version does not download it again, and partial downloads are resumed.
+func installArchive(sources []source.Source, file source.File, goVersion string, opts Options, t target) error {
+	// Abort before downloading anything if the unpacked distribution will not fit
+	if file.Size > 0 {
+		cached := false
+		if path, err := cache.ArchivePath(file.Filename, file.SHA256); err == nil {
+			_, err = os.Stat(path)
+			cached = err == nil
+		}
+		if err := checkDiskSpace(t.dir, requiredSpace(file.Size, cached)); err != nil {
+			return err
+		}
+	}
 
-	if _, err := io.Copy(io.MultiWriter(out, bar), resp.Body); err != nil {
-		return fmt.Errorf("failed to write download to file: %w", err)
+	archive, err := fetchArchive(sources, file, func(checksum string) (string, error) {
+		return cache.ArchivePath(file.Filename, checksum)
+	}, opts)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", file.Filename, err)
+	}
+	if archive.cached {
+		opts.printf("Using cached archive %s\n", archive.path)
+		cache.Touch(archive.path)
+	} else {
+		opts.printf("Downloaded %s from %s\n", file.Filename, archive.source.Name())
 	}
-	out.Close()
 
-	fmt.Printf("Extracting %s...\n", filename)
+	opts.printf("Extracting %s...\n", file.Filename)
 
-	// Extract the archive
-	installPath := filepath.Join(config.VersionsDir, version)
-	if err := extractTarGz(outFilePath, installPath); err != nil {
+	// Extract into a staging directory first so an interrupted install never
+	// leaves a half-populated directory under VersionsDir.
+	stagingPath, err := newStagingDir(goVersion)
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(stagingPath)
+
+	if strings.HasSuffix(file.Filename, ".zip") {
+		err = extractModuleZip(archive.path, filepath.Join(stagingPath, "go"), zipPrefix(file))
+	} else {
+		err = extractTarGz(archive.path, stagingPath)
+	}
+	if err != nil {
 		return fmt.Errorf("failed to extract archive: %w", err)
 	}
 
-	// Clean up the downloaded file
-	defer os.Remove(outFilePath)
+	meta := metadata.Metadata{
+		Version:  goVersion,
+		Method:   metadata.MethodDownload,
+		Source:   archive.source.Name(),
+		Filename: file.Filename,
+		Checksum: archive.checksum,
+		Cached:   archive.cached,
+		OS:       t.goos,
+		Arch:     t.goarch,
+	}
+	if opts.GoProxy {
+		meta.Method = metadata.MethodGoProxy
+	}
+	if err := writeMetadata(stagingPath, meta); err != nil {
+		return err
+	}
 
+	if err := t.commit(stagingPath, goVersion); err != nil {
+		return err
+	}
+	if t.registered && (opts.Dedupe || config.Dedupe) {
+		linkDuplicates(goVersion, opts)
+	}
 	return nil
 }
 
-// extractTarGz extracts a .tar.gz archive to the specified destination.
-func extractTarGz(src, dest string) error {
-	file, err := os.Open(src)
-	if err != nil {
-		return fmt.Errorf("failed to open archive: %w", err)
+// zipPrefix returns the directory holding GOROOT in a zip archive: "go/" in the
+// Windows archives from go.dev, or the module path in golang.org/toolchain zips.
+func zipPrefix(file source.File) string {
+	if strings.HasPrefix(file.Filename, "go") {
+		return "go/"
 	}
-	defer file.Close()
+	return source.ModulePrefix(file)
+}
 
-	gzipReader, err := gzip.NewReader(file)
+// linkDuplicates hard links the files of the freshly installed goVersion to identical
+// files of other installed releases of the same minor version. Failures only warn,
+// since the installation itself is complete.
+func linkDuplicates(goVersion string, opts Options) {
+	installed, err := version.GetLocalVersions()
 	if err != nil {
-		return fmt.Errorf("failed to create gzip reader: %w", err)
+		opts.warnf("skipping deduplication: %v\n", err)
+		return
 	}
-	defer gzipReader.Close()
-
-	tarReader := tar.NewReader(gzipReader)
-
-	for {
-		header, err := tarReader.Next()
-		if err == io.EOF {
-			break // End of archive
-		}
-		if err != nil {
-			return fmt.Errorf("failed to read tar header: %w", err)
+	major := version.MajorVersion(goVersion)
+	var related []string
+	for _, v := range installed {
+		if v != goVersion && version.MajorVersion(v) == major {
+			related = append(related, v)
 		}
+	}
+	if len(related) == 0 {
+		return
+	}
 
-		targetPath := filepath.Join(dest, header.Name)
-
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(targetPath, 0755); err != nil {
-				return fmt.Errorf("failed to create directory %s: %w", targetPath, err)
-			}
-		case tar.TypeReg:
-			// Ensure parent directory exists
-			if err := os.MkdirAll(filepath.Dir(targetPath), 0755); err != nil {
-				return fmt.Errorf("failed to create parent directory for %s: %w", targetPath, err)
-			}
-			outFile, err := os.Create(targetPath)
-			if err != nil {
-				return fmt.Errorf("failed to create file %s: %w", targetPath, err)
-			}
-			// Close the file immediately after writing to release the file descriptor
-			// This is crucial for handling a large number of files and preventing "too many open files" errors.
-			if _, err := io.Copy(outFile, tarReader); err != nil {
-				outFile.Close() // Close on error
-				return fmt.Errorf("failed to write file %s: %w", targetPath, err)
-			}
-			outFile.Close()
-			// Set file permissions
-			if err := os.Chmod(targetPath, header.FileInfo().Mode()); err != nil {
-				return fmt.Errorf("failed to set file permissions for %s: %w", targetPath, err)
-			}
-		case tar.TypeSymlink:
-			// Handle symlinks
-			if err := os.Symlink(header.Linkname, targetPath); err != nil {
-				return fmt.Errorf("failed to create symlink %s: %w", targetPath, err)
-			}
-		default:
-			return fmt.Errorf("unsupported tar entry type: %v in %s", header.Typeflag, header.Name)
-		}
+	result, err := dedupe.Into(goVersion, related)
+	if err != nil {
+		opts.warnf("deduplication failed: %v\n", err)
+		return
 	}
+	opts.printf("Linked %d file(s) shared with %s, saving %.1f MiB\n", result.Linked, strings.Join(related, ", "), float64(result.Saved)/(1<<20))
+}
 
+// verifyChecksum compares a computed SHA-256 digest with the expected hex encoded one.
+func verifyChecksum(sum []byte, expected string) error {
+	actual := hex.EncodeToString(sum)
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("checksum mismatch: expected sha256 %s, got %s", expected, actual)
+	}
 	return nil
 }
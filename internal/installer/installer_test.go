@@ -0,0 +1,240 @@
+package installer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/manifest"
+	"github.com/fun7257/sgv/internal/metadata"
+)
+
+func TestVerifyChecksum(t *testing.T) {
+	sum := sha256.Sum256([]byte("go toolchain"))
+	other := sha256.Sum256([]byte("tampered toolchain"))
+
+	tests := []struct {
+		name     string
+		expected string
+		wantErr  bool
+	}{
+		{"match", hex.EncodeToString(sum[:]), false},
+		{"match uppercase", strings.ToUpper(hex.EncodeToString(sum[:])), false},
+		{"mismatch", hex.EncodeToString(other[:]), true},
+		{"empty", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyChecksum(sum[:], tt.expected)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyChecksum() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func setupTestDirs(t *testing.T) string {
+	t.Helper()
+	tmp := t.TempDir()
+
+	originalVersionsDir, originalStagingDir, originalLocksDir := config.VersionsDir, config.StagingDir, config.LocksDir
+	config.VersionsDir = filepath.Join(tmp, "versions")
+	config.StagingDir = filepath.Join(tmp, "staging")
+	config.LocksDir = filepath.Join(tmp, "locks")
+	t.Cleanup(func() {
+		config.VersionsDir, config.StagingDir, config.LocksDir = originalVersionsDir, originalStagingDir, originalLocksDir
+	})
+	return tmp
+}
+
+func TestCommitStaging(t *testing.T) {
+	t.Run("success", func(t *testing.T) {
+		setupTestDirs(t)
+
+		staging, err := newStagingDir("go1.22.1")
+		if err != nil {
+			t.Fatalf("newStagingDir failed: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(staging, "go", "bin"), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(filepath.Join(staging, "go", "bin", "go"), []byte("#!/bin/sh\n"), 0755); err != nil {
+			t.Fatalf("write go binary stub: %v", err)
+		}
+
+		if err := commitStaging(staging, "go1.22.1"); err != nil {
+			t.Fatalf("commitStaging failed: %v", err)
+		}
+		if _, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1", "go", "bin", "go")); err != nil {
+			t.Fatalf("expected installed go binary: %v", err)
+		}
+		if _, err := os.Stat(staging); !os.IsNotExist(err) {
+			t.Fatalf("expected staging directory to be gone, got: %v", err)
+		}
+	})
+
+	t.Run("missing go binary", func(t *testing.T) {
+		setupTestDirs(t)
+
+		staging, err := newStagingDir("go1.22.1")
+		if err != nil {
+			t.Fatalf("newStagingDir failed: %v", err)
+		}
+		if err := os.MkdirAll(filepath.Join(staging, "go", "src"), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+
+		if err := commitStaging(staging, "go1.22.1"); err == nil {
+			t.Fatalf("expected error for staging directory without go binary")
+		}
+		if _, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1")); !os.IsNotExist(err) {
+			t.Fatalf("expected no installed version, got: %v", err)
+		}
+	})
+}
+
+func TestCleanStaleStaging(t *testing.T) {
+	setupTestDirs(t)
+
+	own, err := newStagingDir("go1.22.1")
+	if err != nil {
+		t.Fatalf("newStagingDir failed: %v", err)
+	}
+
+	// PIDs are capped well below this value on Linux and macOS
+	stale := filepath.Join(config.StagingDir, "999999999-go1.21.0-123")
+	garbage := filepath.Join(config.StagingDir, "not-a-staging-dir")
+	for _, dir := range []string{stale, garbage} {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			t.Fatalf("mkdir %s: %v", dir, err)
+		}
+	}
+
+	cleanStaleStaging()
+
+	if _, err := os.Stat(own); err != nil {
+		t.Errorf("staging directory of running process was removed: %v", err)
+	}
+	for _, dir := range []string{stale, garbage} {
+		if _, err := os.Stat(dir); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed, got: %v", dir, err)
+		}
+	}
+}
+
+func TestInstallWithDedupe(t *testing.T) {
+	server := newToolchainProxy(t, "go1.22.1", "")
+	setupToolchainTest(t, server)
+
+	// An installed patch release sharing a source file with the new one
+	shared := filepath.Join(config.VersionsDir, "go1.22.0", "go", "src", "fmt", "print.go")
+	if err := os.MkdirAll(filepath.Dir(shared), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(shared, []byte("package fmt\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+
+	if err := InstallWith("go1.22.1", Options{GoProxy: true, Dedupe: true, Output: io.Discard}); err != nil {
+		t.Fatalf("InstallWith failed: %v", err)
+	}
+
+	existing, err := os.Stat(shared)
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	installed, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1", "go", "src", "fmt", "print.go"))
+	if err != nil {
+		t.Fatalf("stat: %v", err)
+	}
+	if !os.SameFile(existing, installed) {
+		t.Error("identical file was not linked to the installed patch release")
+	}
+}
+
+func TestReinstallRepairsActiveVersion(t *testing.T) {
+	server := newToolchainProxy(t, "go1.22.1", "")
+	setupToolchainTest(t, server)
+
+	opts := Options{GoProxy: true, Output: io.Discard}
+	if err := InstallWith("go1.22.1", opts); err != nil {
+		t.Fatalf("InstallWith failed: %v", err)
+	}
+
+	// The manifest is recorded next to GOROOT
+	goroot := filepath.Join(config.VersionsDir, "go1.22.1", "go")
+	m, err := manifest.Load(manifest.Path("go1.22.1"))
+	if err != nil {
+		t.Fatalf("failed to load manifest: %v", err)
+	}
+
+	// Damage the installation while a symlink points into it, like the current symlink
+	current := filepath.Join(t.TempDir(), "current")
+	if err := os.Symlink(goroot, current); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "src", "fmt", "print.go"), []byte("edited"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if problems, _ := m.Verify(goroot); len(problems) != 1 {
+		t.Fatalf("expected one problem before reinstalling, got %v", problems)
+	}
+
+	if err := Reinstall("go1.22.1", opts); err != nil {
+		t.Fatalf("Reinstall failed: %v", err)
+	}
+
+	resolved, err := filepath.EvalSymlinks(current)
+	if err != nil {
+		t.Fatalf("symlink into the reinstalled version is broken: %v", err)
+	}
+	if problems, err := m.Verify(resolved); err != nil || len(problems) != 0 {
+		t.Errorf("Verify after reinstall = %v, %v", problems, err)
+	}
+	if entries, _ := os.ReadDir(config.StagingDir); len(entries) != 0 {
+		t.Errorf("expected staging dir to be empty, found %d entries", len(entries))
+	}
+}
+
+func TestInstallToForeignPlatform(t *testing.T) {
+	server := newToolchainProxyFor(t, "go1.22.1", "freebsd", "riscv64", "")
+	setupToolchainTest(t, server)
+
+	dest := filepath.Join(t.TempDir(), "bundle")
+	dir, err := InstallTo("go1.22.1", "freebsd", "riscv64", dest, Options{GoProxy: true, Output: io.Discard})
+	if err != nil {
+		t.Fatalf("InstallTo failed: %v", err)
+	}
+	if want := filepath.Join(dest, "go1.22.1.freebsd-riscv64"); dir != want {
+		t.Errorf("InstallTo returned %s, want %s", dir, want)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "go", "pkg", "tool", "freebsd_riscv64", "compile")); err != nil {
+		t.Errorf("expected the foreign toolchain to be unpacked: %v", err)
+	}
+	if _, err := manifest.Load(filepath.Join(dir, manifest.FileName)); err != nil {
+		t.Errorf("failed to load manifest: %v", err)
+	}
+	meta, err := metadata.Load(filepath.Join(dir, metadata.FileName))
+	if err != nil {
+		t.Fatalf("failed to load install metadata: %v", err)
+	}
+	if meta.OS != "freebsd" || meta.Arch != "riscv64" {
+		t.Errorf("metadata platform = %s/%s, want freebsd/riscv64", meta.OS, meta.Arch)
+	}
+
+	// The bundle is not registered as an installed version
+	if _, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1")); !os.IsNotExist(err) {
+		t.Errorf("expected go1.22.1 not to be installed under %s", config.VersionsDir)
+	}
+
+	if _, err := InstallTo("go1.22.1", "freebsd", "riscv64", dest, Options{GoProxy: true, Output: io.Discard}); err == nil {
+		t.Error("expected an error when the destination already exists")
+	}
+}
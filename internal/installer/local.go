@@ -0,0 +1,191 @@
+package installer
+
+import (
+	"bufio"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// InstallFromArchive installs a Go distribution from a local .tar.gz archive, such as
+// one copied from go.dev/dl onto an air-gapped host. The version is read from the
+// archive's go/VERSION file and returned.
+func InstallFromArchive(archivePath string) (string, error) {
+	cleanStaleStaging()
+
+	stagingPath, err := newStagingDir("local")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stagingPath)
+
+	fmt.Printf("Extracting %s...\n", archivePath)
+	if err := extractTarGz(archivePath, stagingPath); err != nil {
+		return "", fmt.Errorf("failed to extract archive: %w", err)
+	}
+
+	goVersion, err := readGoVersion(filepath.Join(stagingPath, "go"))
+	if err != nil {
+		return "", err
+	}
+
+	sum, err := sha256File(archivePath)
+	if err != nil {
+		return "", err
+	}
+	if err := writeMetadata(stagingPath, metadata.Metadata{
+		Version:  goVersion,
+		Method:   metadata.MethodArchive,
+		Source:   absPath(archivePath),
+		Filename: filepath.Base(archivePath),
+		Checksum: hex.EncodeToString(sum),
+	}); err != nil {
+		return "", err
+	}
+
+	if err := commitStagingLocked(stagingPath, goVersion); err != nil {
+		return "", err
+	}
+	return goVersion, nil
+}
+
+// InstallFromDir installs a copy of an already unpacked Go distribution. dir may be
+// the GOROOT itself or a directory containing it as "go". The version is read from
+// the GOROOT's VERSION file and returned.
+func InstallFromDir(dir string) (string, error) {
+	goroot := dir
+	if err := version.ValidateGoRoot(goroot); err != nil {
+		goroot = filepath.Join(dir, "go")
+		if nestedErr := version.ValidateGoRoot(goroot); nestedErr != nil {
+			return "", fmt.Errorf("%s does not contain a Go distribution: %w", dir, err)
+		}
+	}
+
+	goVersion, err := readGoVersion(goroot)
+	if err != nil {
+		return "", err
+	}
+
+	cleanStaleStaging()
+
+	stagingPath, err := newStagingDir(goVersion)
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(stagingPath)
+
+	fmt.Printf("Copying %s...\n", goroot)
+	if err := copyTree(goroot, filepath.Join(stagingPath, "go")); err != nil {
+		return "", fmt.Errorf("failed to copy %s: %w", goroot, err)
+	}
+	if err := writeMetadata(stagingPath, metadata.Metadata{
+		Version: goVersion,
+		Method:  metadata.MethodDirectory,
+		Source:  absPath(goroot),
+	}); err != nil {
+		return "", err
+	}
+
+	if err := commitStagingLocked(stagingPath, goVersion); err != nil {
+		return "", err
+	}
+	return goVersion, nil
+}
+
+// absPath returns the absolute form of path, or path itself if that fails.
+func absPath(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// readGoVersion returns the version name (e.g., "go1.22.1") from the first line of goroot/VERSION.
+func readGoVersion(goroot string) (string, error) {
+	versionFile := filepath.Join(goroot, "VERSION")
+	f, err := os.Open(versionFile)
+	if err != nil {
+		return "", fmt.Errorf("failed to determine Go version: %w", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Scan()
+	if err := scanner.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", versionFile, err)
+	}
+
+	goVersion := strings.TrimSpace(scanner.Text())
+	if !strings.HasPrefix(goVersion, "go") || strings.ContainsAny(goVersion, " /\\") || goVersion == "go" {
+		return "", fmt.Errorf("unexpected version %q in %s", goVersion, versionFile)
+	}
+	return goVersion, nil
+}
+
+// copyTree recursively copies src to dst, preserving file modes, modification times and symlinks.
+func copyTree(src, dst string) error {
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, rel)
+
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0755)
+		case fi.Mode()&os.ModeSymlink != 0:
+			link, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(link, target)
+		case fi.Mode().IsRegular():
+			if err := copyFile(path, target, fi.Mode().Perm()); err != nil {
+				return err
+			}
+			return os.Chtimes(target, fi.ModTime(), fi.ModTime())
+		default:
+			return nil // Skip sockets, devices and the like
+		}
+	})
+}
+
+// copyFile copies the regular file src to dst with the given permissions.
+func copyFile(src, dst string, perm os.FileMode) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_EXCL|os.O_WRONLY, perm)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	if err := out.Close(); err != nil {
+		return err
+	}
+	// OpenFile applies the umask, so set the exact permissions explicitly
+	return os.Chmod(dst, perm)
+}
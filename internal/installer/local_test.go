@@ -0,0 +1,102 @@
+package installer
+
+import (
+	"archive/tar"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/metadata"
+)
+
+func TestInstallFromArchive(t *testing.T) {
+	tmp := setupTestDirs(t)
+
+	archive := writeTarGz(t, tmp, []tarEntry{
+		{name: "go/", typeflag: tar.TypeDir},
+		{name: "go/VERSION", typeflag: tar.TypeReg, body: "go1.22.1\ntime 2024-03-01T19:43:56Z\n"},
+		{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755},
+	}, time.Now())
+
+	goVersion, err := InstallFromArchive(archive)
+	if err != nil {
+		t.Fatalf("InstallFromArchive failed: %v", err)
+	}
+	if goVersion != "go1.22.1" {
+		t.Errorf("InstallFromArchive() = %q, want go1.22.1", goVersion)
+	}
+	if _, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1", "go", "bin", "go")); err != nil {
+		t.Errorf("expected installed go binary: %v", err)
+	}
+
+	meta, err := metadata.Load(metadata.Path("go1.22.1"))
+	if err != nil {
+		t.Fatalf("failed to load install metadata: %v", err)
+	}
+	sum, _ := sha256File(archive)
+	if meta.Method != metadata.MethodArchive || meta.Source != archive || meta.Checksum != hex.EncodeToString(sum) || meta.InstalledAt.IsZero() {
+		t.Errorf("unexpected install metadata %+v", meta)
+	}
+
+	if _, err := InstallFromArchive(archive); err == nil {
+		t.Errorf("expected error when installing an already installed version")
+	}
+}
+
+func TestInstallFromArchiveWithoutVersion(t *testing.T) {
+	tmp := setupTestDirs(t)
+
+	archive := writeTarGz(t, tmp, []tarEntry{
+		{name: "go/bin/go", typeflag: tar.TypeReg, body: "binary", mode: 0755},
+	}, time.Now())
+
+	if _, err := InstallFromArchive(archive); err == nil {
+		t.Fatalf("expected error for archive without go/VERSION")
+	}
+	entries, _ := os.ReadDir(config.VersionsDir)
+	if len(entries) != 0 {
+		t.Errorf("expected nothing installed, found %d entries", len(entries))
+	}
+}
+
+func TestInstallFromDir(t *testing.T) {
+	tmp := setupTestDirs(t)
+
+	// A directory containing the GOROOT as "go", like an unpacked archive
+	goroot := filepath.Join(tmp, "unpacked", "go")
+	if err := os.MkdirAll(filepath.Join(goroot, "bin"), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "VERSION"), []byte("go1.21.13\n"), 0644); err != nil {
+		t.Fatalf("write VERSION: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "bin", "go"), []byte("binary"), 0755); err != nil {
+		t.Fatalf("write go binary stub: %v", err)
+	}
+	if err := os.Symlink("bin/go", filepath.Join(goroot, "go-link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+
+	goVersion, err := InstallFromDir(filepath.Dir(goroot))
+	if err != nil {
+		t.Fatalf("InstallFromDir failed: %v", err)
+	}
+	if goVersion != "go1.21.13" {
+		t.Errorf("InstallFromDir() = %q, want go1.21.13", goVersion)
+	}
+
+	installed := filepath.Join(config.VersionsDir, "go1.21.13", "go")
+	fi, err := os.Stat(filepath.Join(installed, "bin", "go"))
+	if err != nil {
+		t.Fatalf("expected installed go binary: %v", err)
+	}
+	if fi.Mode().Perm() != 0755 {
+		t.Errorf("unexpected go binary mode %v", fi.Mode().Perm())
+	}
+	if link, err := os.Readlink(filepath.Join(installed, "go-link")); err != nil || link != "bin/go" {
+		t.Errorf("symlink not preserved: %q, %v", link, err)
+	}
+}
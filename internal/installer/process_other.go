@@ -0,0 +1,10 @@
+//go:build !unix
+
+package installer
+
+// processAlive reports whether a process with the given PID exists. Without a way
+// to probe it, every process is assumed to be alive, so staging directories of
+// other installs are never removed.
+func processAlive(pid int) bool {
+	return pid > 0
+}
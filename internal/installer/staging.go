@@ -0,0 +1,201 @@
+package installer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
+	"github.com/fun7257/sgv/internal/manifest"
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// newStagingDir creates a fresh directory under config.StagingDir to extract goVersion into.
+// The directory name starts with the PID of the current process so that stale
+// directories can be told apart from those of installs still in progress.
+func newStagingDir(goVersion string) (string, error) {
+	if err := os.MkdirAll(config.StagingDir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create staging directory %s: %w", config.StagingDir, err)
+	}
+
+	dir, err := os.MkdirTemp(config.StagingDir, fmt.Sprintf("%d-%s-", os.Getpid(), goVersion))
+	if err != nil {
+		return "", fmt.Errorf("failed to create staging directory for %s: %w", goVersion, err)
+	}
+	return dir, nil
+}
+
+// prepareStaging checks that stagingPath holds a usable Go distribution and records
+// its manifest next to it, so that the installation can be verified later.
+func prepareStaging(stagingPath, goVersion string) error {
+	goroot := filepath.Join(stagingPath, "go")
+	if err := version.ValidateGoRoot(goroot); err != nil {
+		return fmt.Errorf("staged installation of %s is incomplete: %w", goVersion, err)
+	}
+	return writeManifest(stagingPath, goVersion)
+}
+
+// writeManifest records the manifest of the distribution staged in stagingPath.
+func writeManifest(stagingPath, goVersion string) error {
+	m, err := manifest.Create(filepath.Join(stagingPath, "go"))
+	if err != nil {
+		return fmt.Errorf("failed to record manifest of %s: %w", goVersion, err)
+	}
+	return m.Save(filepath.Join(stagingPath, manifest.FileName))
+}
+
+// writeMetadata records how the version staged in stagingPath was installed. The
+// time, sgv version and (unless set) the current platform are filled in.
+func writeMetadata(stagingPath string, meta metadata.Metadata) error {
+	meta.InstalledAt = time.Now().UTC().Truncate(time.Second)
+	meta.SgvVersion = version.GetSGVVersion()
+	if meta.OS == "" {
+		meta.OS, meta.Arch = runtime.GOOS, runtime.GOARCH
+	}
+	return meta.Save(filepath.Join(stagingPath, metadata.FileName))
+}
+
+// commitStaging prepares stagingPath and atomically renames it to <VersionsDir>/<goVersion>.
+func commitStaging(stagingPath, goVersion string) error {
+	if err := prepareStaging(stagingPath, goVersion); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(config.VersionsDir, 0755); err != nil {
+		return fmt.Errorf("failed to create versions directory: %w", err)
+	}
+
+	installPath := filepath.Join(config.VersionsDir, goVersion)
+	if _, err := os.Lstat(installPath); err == nil {
+		return fmt.Errorf("version %s is already installed at %s", goVersion, installPath)
+	} else if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to check installation path %s: %w", installPath, err)
+	}
+
+	// StagingDir and VersionsDir both live under SgvRoot, so this is a same-filesystem rename
+	if err := os.Rename(stagingPath, installPath); err != nil {
+		return fmt.Errorf("failed to move %s into place: %w", goVersion, err)
+	}
+	return nil
+}
+
+// replaceStaging prepares stagingPath and exchanges it with the existing installation
+// of goVersion, which ends up in stagingPath. Where the platform supports it, the
+// exchange is a single atomic rename, so the version (and the current symlink, if
+// it points there) stays usable throughout.
+func replaceStaging(stagingPath, goVersion string) error {
+	if err := prepareStaging(stagingPath, goVersion); err != nil {
+		return err
+	}
+
+	installPath := filepath.Join(config.VersionsDir, goVersion)
+	if _, err := os.Lstat(installPath); os.IsNotExist(err) {
+		return commitStaging(stagingPath, goVersion)
+	}
+
+	if err := exchange(stagingPath, installPath); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", goVersion, err)
+	}
+	return nil
+}
+
+// commitBundle checks that stagingPath holds a Go distribution for goos, records its
+// manifest and moves it to dir, which may be on another filesystem than the staging
+// directory. The go binary cannot be run, since it is usually built for another platform.
+func commitBundle(stagingPath, goVersion, goos, dir string) error {
+	goBin := filepath.Join(stagingPath, "go", "bin", "go")
+	if goos == "windows" {
+		goBin += ".exe"
+	}
+	if fi, err := os.Stat(goBin); err != nil || !fi.Mode().IsRegular() {
+		return fmt.Errorf("staged installation of %s is incomplete: go binary not found at %s", goVersion, goBin)
+	}
+	if err := writeManifest(stagingPath, goVersion); err != nil {
+		return err
+	}
+	// Staging directories are private, the bundle is not
+	if err := os.Chmod(stagingPath, 0755); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dir), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(dir), err)
+	}
+	if _, err := os.Lstat(dir); err == nil {
+		return fmt.Errorf("%s already exists", dir)
+	}
+
+	err := os.Rename(stagingPath, dir)
+	if !errors.Is(err, syscall.EXDEV) {
+		if err != nil {
+			return fmt.Errorf("failed to move %s into place: %w", goVersion, err)
+		}
+		return nil
+	}
+
+	// Copy next to dir first, so that dir only ever appears complete
+	tmp, err := os.MkdirTemp(filepath.Dir(dir), "."+filepath.Base(dir)+".sgv-")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary directory for %s: %w", goVersion, err)
+	}
+	if err := copyTree(stagingPath, tmp); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("failed to copy %s to %s: %w", goVersion, filepath.Dir(dir), err)
+	}
+	if err := os.Rename(tmp, dir); err != nil {
+		_ = os.RemoveAll(tmp)
+		return fmt.Errorf("failed to move %s into place: %w", goVersion, err)
+	}
+	return nil
+}
+
+// exchangeByRename swaps the directories a and b with two renames, for filesystems
+// without an atomic exchange. b is briefly missing in between.
+func exchangeByRename(a, b string) error {
+	tmp := a + ".old"
+	if err := os.Rename(b, tmp); err != nil {
+		return err
+	}
+	if err := os.Rename(a, b); err != nil {
+		_ = os.Rename(tmp, b)
+		return err
+	}
+	return os.Rename(tmp, a)
+}
+
+// commitStagingLocked is commitStaging for installs that only learn their version
+// from the staged distribution, taking the version's install lock for the commit.
+func commitStagingLocked(stagingPath, goVersion string) error {
+	l, err := lock.Version(goVersion)
+	if err != nil {
+		return err
+	}
+	defer l.Release()
+
+	return commitStaging(stagingPath, goVersion)
+}
+
+// cleanStaleStaging removes staging directories whose owning sgv process is no longer running (best effort).
+func cleanStaleStaging() {
+	entries, err := os.ReadDir(config.StagingDir)
+	if err != nil {
+		return // Nothing staged yet
+	}
+
+	for _, entry := range entries {
+		pidStr, _, found := strings.Cut(entry.Name(), "-")
+		pid, err := strconv.Atoi(pidStr)
+		if found && err == nil && (pid == os.Getpid() || processAlive(pid)) {
+			continue // The install is still running
+		}
+		_ = os.RemoveAll(filepath.Join(config.StagingDir, entry.Name()))
+	}
+}
@@ -0,0 +1,41 @@
+package installer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/version"
+
+	"golang.org/x/mod/sumdb/dirhash"
+)
+
+// minToolchainVersion is the first Go version published as a toolchain module.
+const minToolchainVersion = "go1.21rc2"
+
+// checkToolchainVersion reports an error if goVersion was never published as a toolchain module.
+func checkToolchainVersion(goVersion string) error {
+	if !version.IsValid(goVersion) {
+		return fmt.Errorf("invalid Go version %q", goVersion)
+	}
+	if version.Compare(goVersion, minToolchainVersion) < 0 {
+		return fmt.Errorf("%s is not available from the module proxy: toolchain modules are only published for %s and later", goVersion, minToolchainVersion)
+	}
+	return nil
+}
+
+// isModuleHash reports whether checksum is a go.sum style hash rather than a SHA-256.
+func isModuleHash(checksum string) bool {
+	return strings.HasPrefix(checksum, "h1:")
+}
+
+// verifyModuleHash compares the go.sum hash of the module zip at path with expected.
+func verifyModuleHash(path, expected string) error {
+	actual, err := dirhash.HashZip(path, dirhash.Hash1)
+	if err != nil {
+		return fmt.Errorf("failed to hash %s: %w", path, err)
+	}
+	if actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
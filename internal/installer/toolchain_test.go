@@ -0,0 +1,195 @@
+package installer
+
+import (
+	"archive/zip"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/metadata"
+	"github.com/fun7257/sgv/internal/source"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/dirhash"
+	"golang.org/x/mod/sumdb/note"
+)
+
+// newToolchainProxy serves a fake golang.org/toolchain zip for goVersion together
+// with a checksum database lookup answering with hash (or the zip's real hash if empty).
+func newToolchainProxy(t *testing.T, goVersion, hash string) *httptest.Server {
+	t.Helper()
+	return newToolchainProxyFor(t, goVersion, runtime.GOOS, runtime.GOARCH, hash)
+}
+
+// newToolchainProxyFor is newToolchainProxy for the toolchain of goos/goarch.
+func newToolchainProxyFor(t *testing.T, goVersion, goos, goarch, hash string) *httptest.Server {
+	t.Helper()
+
+	file := source.ToolchainFile(goVersion, goos, goarch)
+	modVersion := strings.TrimSuffix(file.Filename, ".zip")
+	prefix := source.ModulePrefix(file)
+
+	zipPath := filepath.Join(t.TempDir(), "toolchain.zip")
+	f, err := os.Create(zipPath)
+	if err != nil {
+		t.Fatalf("create zip: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	for name, content := range map[string]string{
+		"VERSION": goVersion + "\ntime 2024-03-01T00:00:00Z\n",
+		"bin/go":  "#!/bin/sh\n",
+		"pkg/tool/" + goos + "_" + goarch + "/compile": "tool",
+		"src/fmt/print.go": "package fmt\n",
+	} {
+		w, err := zw.Create(prefix + name)
+		if err != nil {
+			t.Fatalf("create zip entry: %v", err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatalf("write zip entry: %v", err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("close zip: %v", err)
+	}
+	f.Close()
+
+	if hash == "" {
+		hash, err = dirhash.HashZip(zipPath, dirhash.Hash1)
+		if err != nil {
+			t.Fatalf("hash zip: %v", err)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+source.ToolchainModule+"/@v/"+modVersion+".zip", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, zipPath)
+	})
+	mux.Handle("/sumdb/", http.StripPrefix("/sumdb", newTestSumDB(t, func(path, vers string) string {
+		if path == source.ToolchainModule && vers == modVersion {
+			return hash
+		}
+		return ""
+	})))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// testSumDBKey is the verifier key of the checksum database newTestSumDB serves.
+var testSumDBKey string
+
+// newTestSumDB serves a signed checksum database recording the zip hashes returned
+// by hash. Its verifier key is stored in testSumDBKey.
+func newTestSumDB(t *testing.T, hash func(path, vers string) string) http.Handler {
+	t.Helper()
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	if err != nil {
+		t.Fatalf("generate sumdb key: %v", err)
+	}
+	testSumDBKey = vkey
+
+	return sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		h := hash(path, vers)
+		if h == "" {
+			return nil, fmt.Errorf("%s@%s not found", path, vers)
+		}
+		return []byte(path + " " + vers + " " + h + "\n" +
+			path + " " + vers + "/go.mod h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"), nil
+	}))
+}
+
+// toolPath returns the location of the compiler below GOROOT.
+func toolPath() string {
+	return "pkg/tool/" + runtime.GOOS + "_" + runtime.GOARCH + "/compile"
+}
+
+func setupToolchainTest(t *testing.T, server *httptest.Server) {
+	t.Helper()
+	tmp := setupTestDirs(t)
+	setupDownloadTest(t, 1)
+
+	originalCacheDir, originalProxy, originalSumDB := config.CacheDir, config.GoProxy, config.GoSumDB
+	config.CacheDir = filepath.Join(tmp, "cache")
+	config.GoProxy = server.URL
+	// The fake proxy serves the checksum database as well so nothing leaves the test
+	config.GoSumDB = testSumDBKey + " " + server.URL + "/sumdb"
+	t.Cleanup(func() {
+		config.CacheDir, config.GoProxy, config.GoSumDB = originalCacheDir, originalProxy, originalSumDB
+	})
+}
+
+func TestInstallWithGoProxy(t *testing.T) {
+	server := newToolchainProxy(t, "go1.22.1", "")
+	setupToolchainTest(t, server)
+
+	if err := InstallWith("go1.22.1", Options{GoProxy: true}); err != nil {
+		t.Fatalf("InstallWith failed: %v", err)
+	}
+
+	goroot := filepath.Join(config.VersionsDir, "go1.22.1", "go")
+	tests := []struct {
+		path string
+		exec bool
+	}{
+		{"bin/go", true},
+		{toolPath(), true},
+		{"src/fmt/print.go", false},
+		{"VERSION", false},
+	}
+	for _, tt := range tests {
+		fi, err := os.Stat(filepath.Join(goroot, tt.path))
+		if err != nil {
+			t.Fatalf("expected %s to be installed: %v", tt.path, err)
+		}
+		if got := fi.Mode().Perm()&0111 != 0; got != tt.exec {
+			t.Errorf("%s executable = %v, want %v", tt.path, got, tt.exec)
+		}
+	}
+
+	meta, err := metadata.Load(metadata.Path("go1.22.1"))
+	if err != nil {
+		t.Fatalf("failed to load install metadata: %v", err)
+	}
+	if meta.Method != metadata.MethodGoProxy || meta.Source != server.URL || !strings.HasPrefix(meta.Checksum, "h1:") ||
+		meta.Filename != source.ToolchainFile("go1.22.1", runtime.GOOS, runtime.GOARCH).Filename {
+		t.Errorf("unexpected install metadata %+v", meta)
+	}
+
+	entries, err := os.ReadDir(config.StagingDir)
+	if err != nil {
+		t.Fatalf("read staging dir: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected staging dir to be empty, found %d entries", len(entries))
+	}
+}
+
+func TestInstallWithGoProxyHashMismatch(t *testing.T) {
+	server := newToolchainProxy(t, "go1.22.1", "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU=")
+	setupToolchainTest(t, server)
+
+	err := InstallWith("go1.22.1", Options{GoProxy: true})
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(config.VersionsDir, "go1.22.1")); !os.IsNotExist(err) {
+		t.Errorf("expected go1.22.1 not to be installed")
+	}
+}
+
+func TestInstallWithGoProxyTooOld(t *testing.T) {
+	setupTestDirs(t)
+
+	err := InstallWith("go1.20.5", Options{GoProxy: true})
+	if err == nil || !strings.Contains(err.Error(), "only published for") {
+		t.Fatalf("expected an error for a version without toolchain module, got %v", err)
+	}
+}
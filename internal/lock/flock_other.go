@@ -0,0 +1,20 @@
+//go:build !unix
+
+package lock
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+)
+
+// tryLock takes an exclusive lock on f without waiting. File locks are only
+// implemented with flock(2).
+func tryLock(f *os.File) (bool, error) {
+	return false, fmt.Errorf("file locks are not supported on %s", runtime.GOOS)
+}
+
+// unlock releases the lock held on f.
+func unlock(f *os.File) error {
+	return fmt.Errorf("file locks are not supported on %s", runtime.GOOS)
+}
@@ -0,0 +1,129 @@
+// Package lock serializes sgv processes with advisory file locks under
+// config.LocksDir. Installs take a lock per version, while switching versions and
+// writing environment files take the global lock. Code that needs both takes the
+// global lock first. Locks are not reentrant.
+//
+// Locks are held with flock(2), so the kernel releases them when the holding
+// process exits, even if it crashes: a lock file left on disk is never stale.
+package lock
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// globalName is the name of the lock guarding the current symlink and env files.
+const globalName = "global"
+
+// pollInterval is how often a busy lock is retried.
+const pollInterval = 100 * time.Millisecond
+
+// Lock is a held lock.
+type Lock struct {
+	file *os.File
+}
+
+// Version locks the installation of goVersion.
+func Version(goVersion string) (*Lock, error) {
+	return VersionTo(goVersion, os.Stderr)
+}
+
+// VersionTo is like Version, but reports waiting for another process to w, e.g. so
+// that the notice doesn't break up the progress bars of concurrent installs.
+func VersionTo(goVersion string, w io.Writer) (*Lock, error) {
+	return acquire(goVersion, config.LockTimeout, w)
+}
+
+// Global locks the state shared by all versions: the current symlink and env files.
+func Global() (*Lock, error) {
+	return Acquire(globalName, config.LockTimeout)
+}
+
+// Acquire takes the lock called name, waiting up to timeout for other processes
+// to release it. While waiting, it reports the PID of the holder on stderr once.
+func Acquire(name string, timeout time.Duration) (*Lock, error) {
+	return acquire(name, timeout, os.Stderr)
+}
+
+// acquire is Acquire, reporting waiting to w.
+func acquire(name string, timeout time.Duration, w io.Writer) (*Lock, error) {
+	if config.LocksDir == "" {
+		return nil, fmt.Errorf("locks directory is not configured")
+	}
+	if strings.ContainsAny(name, `/\`) || name == "" || name == "." || name == ".." {
+		return nil, fmt.Errorf("invalid lock name %q", name)
+	}
+	if err := os.MkdirAll(config.LocksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create locks directory: %w", err)
+	}
+
+	path := filepath.Join(config.LocksDir, name+".lock")
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open lock file %s: %w", path, err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	notified := false
+	for {
+		locked, err := tryLock(f)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("failed to lock %s: %w", path, err)
+		}
+		if locked {
+			break
+		}
+
+		holder := describeHolder(path)
+		if time.Now().After(deadline) {
+			f.Close()
+			return nil, fmt.Errorf("timed out after %s waiting for the %s lock held by %s", timeout, name, holder)
+		}
+		if !notified {
+			fmt.Fprintf(w, "Waiting for %s to release the %s lock...\n", holder, name)
+			notified = true
+		}
+		time.Sleep(pollInterval)
+	}
+
+	// Record the holder so that waiting processes can tell the user who it is
+	if err := f.Truncate(0); err == nil {
+		_, _ = f.WriteAt([]byte(strconv.Itoa(os.Getpid())+"\n"), 0)
+	}
+	return &Lock{file: f}, nil
+}
+
+// Release releases the lock. The lock file stays in place so that every process
+// keeps locking the same file.
+func (l *Lock) Release() error {
+	if l == nil || l.file == nil {
+		return nil
+	}
+	err := unlock(l.file)
+	if closeErr := l.file.Close(); err == nil {
+		err = closeErr
+	}
+	l.file = nil
+	return err
+}
+
+// describeHolder names the process holding the lock file at path.
+func describeHolder(path string) string {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "another sgv process"
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	if err != nil || pid <= 0 {
+		return "another sgv process"
+	}
+	return fmt.Sprintf("sgv process %d", pid)
+}
@@ -0,0 +1,109 @@
+package lock
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+func setupLocksDir(t *testing.T) {
+	t.Helper()
+	original := config.LocksDir
+	config.LocksDir = t.TempDir()
+	t.Cleanup(func() { config.LocksDir = original })
+}
+
+func TestAcquireExcludesOtherHolders(t *testing.T) {
+	setupLocksDir(t)
+
+	l, err := Acquire("go1.22.1", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+
+	// flock locks belong to the open file, so a second open conflicts even in-process
+	_, err = Acquire("go1.22.1", 200*time.Millisecond)
+	if err == nil {
+		t.Fatalf("expected second Acquire to time out")
+	}
+	if want := fmt.Sprintf("sgv process %d", os.Getpid()); !strings.Contains(err.Error(), want) {
+		t.Errorf("error %q does not name the holder (%s)", err, want)
+	}
+
+	// Other locks are independent
+	other, err := Acquire("go1.23.2", 0)
+	if err != nil {
+		t.Fatalf("Acquire of another lock failed: %v", err)
+	}
+	_ = other.Release()
+
+	if err := l.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	again, err := Acquire("go1.22.1", 0)
+	if err != nil {
+		t.Fatalf("Acquire after Release failed: %v", err)
+	}
+	_ = again.Release()
+}
+
+func TestAcquireWaitsForRelease(t *testing.T) {
+	setupLocksDir(t)
+
+	l, err := Global()
+	if err != nil {
+		t.Fatalf("Global failed: %v", err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = l.Release()
+	}()
+
+	waiter, err := Acquire(globalName, 5*time.Second)
+	if err != nil {
+		t.Fatalf("expected Acquire to succeed once the lock is released: %v", err)
+	}
+	_ = waiter.Release()
+}
+
+func TestVersionToReportsWaitingToWriter(t *testing.T) {
+	setupLocksDir(t)
+	original := config.LockTimeout
+	config.LockTimeout = 5 * time.Second
+	t.Cleanup(func() { config.LockTimeout = original })
+
+	l, err := Acquire("go1.22.1", time.Second)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		_ = l.Release()
+	}()
+
+	var out bytes.Buffer
+	waiter, err := VersionTo("go1.22.1", &out)
+	if err != nil {
+		t.Fatalf("expected VersionTo to succeed once the lock is released: %v", err)
+	}
+	_ = waiter.Release()
+	if !strings.Contains(out.String(), "Waiting for sgv process") {
+		t.Errorf("wait notice %q not written to the given writer", out.String())
+	}
+}
+
+func TestAcquireRejectsInvalidNames(t *testing.T) {
+	setupLocksDir(t)
+
+	for _, name := range []string{"", ".", "..", "../escape", "a/b"} {
+		if l, err := Acquire(name, 0); err == nil {
+			_ = l.Release()
+			t.Errorf("expected error for lock name %q", name)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+// Package manifest records the files of an installed Go version so that damage
+// (edited, missing or extra files) can be detected later.
+//
+// A manifest is a text file with one tab separated line per file:
+//
+//	<sha256 or "link:<target>">	<octal permissions>	<slash separated path>
+package manifest
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// FileName is the name of the manifest inside a version directory, next to "go".
+const FileName = "manifest"
+
+// header is the first line of every manifest.
+const header = "# sgv manifest v1"
+
+// Entry describes one file or symlink below GOROOT.
+type Entry struct {
+	Path string      // Relative to GOROOT, slash separated
+	Mode fs.FileMode // Permission bits
+	Hash string      // Hex SHA-256 of the content, or "link:<target>" for symlinks
+}
+
+// Manifest lists the files of an installation, sorted by path.
+type Manifest struct {
+	Entries []Entry
+}
+
+// Problem kinds reported by Verify.
+const (
+	Missing    = "missing"
+	Modified   = "modified"
+	ModeChange = "mode changed"
+	Unexpected = "unexpected"
+)
+
+// Problem is a difference between an installation and its manifest.
+type Problem struct {
+	Path string
+	Kind string
+}
+
+func (p Problem) String() string {
+	return p.Kind + ": " + p.Path
+}
+
+// Path returns the location of the manifest of an installed version.
+func Path(goVersion string) string {
+	return filepath.Join(config.VersionsDir, goVersion, FileName)
+}
+
+// Create records every regular file and symlink below goroot.
+func Create(goroot string) (*Manifest, error) {
+	entries, err := scan(goroot)
+	if err != nil {
+		return nil, err
+	}
+
+	m := &Manifest{}
+	for _, path := range sortedKeys(entries) {
+		m.Entries = append(m.Entries, entries[path])
+	}
+	return m, nil
+}
+
+// Save writes the manifest to path.
+func (m *Manifest) Save(path string) error {
+	var sb strings.Builder
+	sb.WriteString(header + "\n")
+	for _, e := range m.Entries {
+		fmt.Fprintf(&sb, "%s\t%o\t%s\n", e.Hash, e.Mode, e.Path)
+	}
+	if err := os.WriteFile(path, []byte(sb.String()), 0644); err != nil {
+		return fmt.Errorf("failed to write manifest: %w", err)
+	}
+	return nil
+}
+
+// Load reads the manifest at path.
+func Load(path string) (*Manifest, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := &Manifest{}
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 {
+			if text != header {
+				return nil, fmt.Errorf("%s is not an sgv manifest", path)
+			}
+			continue
+		}
+
+		fields := strings.SplitN(text, "\t", 3)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("%s:%d: malformed entry", path, line)
+		}
+		mode, err := strconv.ParseUint(fields[1], 8, 32)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: invalid mode %q", path, line, fields[1])
+		}
+		m.Entries = append(m.Entries, Entry{Path: fields[2], Mode: fs.FileMode(mode), Hash: fields[0]})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest %s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Verify compares the files below goroot with the manifest and returns every
+// difference, sorted by path.
+func (m *Manifest) Verify(goroot string) ([]Problem, error) {
+	actual, err := scan(goroot)
+	if err != nil {
+		return nil, err
+	}
+
+	var problems []Problem
+	for _, want := range m.Entries {
+		got, ok := actual[want.Path]
+		switch {
+		case !ok:
+			problems = append(problems, Problem{want.Path, Missing})
+		case got.Hash != want.Hash:
+			problems = append(problems, Problem{want.Path, Modified})
+		case got.Mode != want.Mode:
+			problems = append(problems, Problem{want.Path, ModeChange})
+		}
+		delete(actual, want.Path)
+	}
+	for _, path := range sortedKeys(actual) {
+		problems = append(problems, Problem{path, Unexpected})
+	}
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Path < problems[j].Path
+	})
+	return problems, nil
+}
+
+// scan hashes every regular file and symlink below goroot, keyed by relative path.
+func scan(goroot string) (map[string]Entry, error) {
+	entries := make(map[string]Entry)
+	err := filepath.WalkDir(goroot, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(goroot, path)
+		if err != nil {
+			return err
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		e := Entry{Path: filepath.ToSlash(rel), Mode: fi.Mode().Perm()}
+		switch {
+		case d.Type()&fs.ModeSymlink != 0:
+			target, err := os.Readlink(path)
+			if err != nil {
+				return err
+			}
+			e.Hash = "link:" + target
+		case d.Type().IsRegular():
+			if e.Hash, err = hashFile(path); err != nil {
+				return err
+			}
+		default:
+			return nil // Sockets, devices and the like are not part of a Go distribution
+		}
+		entries[e.Path] = e
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan %s: %w", goroot, err)
+	}
+	return entries, nil
+}
+
+func hashFile(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func sortedKeys(entries map[string]Entry) []string {
+	keys := make([]string, 0, len(entries))
+	for k := range entries {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,102 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTree(t *testing.T, goroot string) {
+	t.Helper()
+	files := map[string]os.FileMode{
+		"VERSION":          0644,
+		"bin/go":           0755,
+		"src/fmt/print.go": 0644,
+	}
+	for name, mode := range files {
+		path := filepath.Join(goroot, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte(name), mode); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	if err := os.Symlink("go", filepath.Join(goroot, "bin", "go-link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+}
+
+func TestSaveAndLoad(t *testing.T) {
+	goroot := filepath.Join(t.TempDir(), "go")
+	writeTree(t, goroot)
+
+	m, err := Create(goroot)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+	if len(m.Entries) != 4 || m.Entries[0].Path != "VERSION" || m.Entries[2].Hash != "link:go" || m.Entries[1].Mode != 0755 {
+		t.Fatalf("unexpected entries %+v", m.Entries)
+	}
+
+	path := filepath.Join(t.TempDir(), FileName)
+	if err := m.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+	loaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(loaded, m) {
+		t.Errorf("Load = %+v, want %+v", loaded, m)
+	}
+
+	if err := os.WriteFile(path, []byte("not a manifest\n"), 0644); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error loading a file without manifest header")
+	}
+}
+
+func TestVerify(t *testing.T) {
+	goroot := filepath.Join(t.TempDir(), "go")
+	writeTree(t, goroot)
+
+	m, err := Create(goroot)
+	if err != nil {
+		t.Fatalf("Create failed: %v", err)
+	}
+
+	if problems, err := m.Verify(goroot); err != nil || len(problems) != 0 {
+		t.Fatalf("Verify of an intact tree = %v, %v", problems, err)
+	}
+
+	if err := os.WriteFile(filepath.Join(goroot, "src/fmt/print.go"), []byte("edited"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(filepath.Join(goroot, "VERSION")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(filepath.Join(goroot, "bin/go"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(goroot, "src/fmt/extra.go"), []byte("extra"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	problems, err := m.Verify(goroot)
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	want := []Problem{
+		{"VERSION", Missing},
+		{"bin/go", ModeChange},
+		{"src/fmt/extra.go", Unexpected},
+		{"src/fmt/print.go", Modified},
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("Verify = %v, want %v", problems, want)
+	}
+}
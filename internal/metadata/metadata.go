@@ -0,0 +1,72 @@
+// Package metadata records where each installed Go version came from, in an
+// install.json file next to its GOROOT.
+package metadata
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// FileName is the name of the metadata file inside a version directory, next to "go".
+const FileName = "install.json"
+
+// Install methods.
+const (
+	MethodDownload  = "download"  // Archive from the configured download sources
+	MethodGoProxy   = "goproxy"   // golang.org/toolchain module from a Go module proxy
+	MethodArchive   = "archive"   // Local archive (sgv install --from <archive>)
+	MethodDirectory = "directory" // Copy of an unpacked distribution (sgv install --from <dir>)
+	MethodSource    = "source"    // Built from a git ref (sgv install tip / --source)
+)
+
+// Metadata describes how a version was installed.
+type Metadata struct {
+	Version     string    `json:"version"`
+	Method      string    `json:"method"`
+	Source      string    `json:"source,omitempty"`   // Download source, module proxy, local path or git repository
+	Filename    string    `json:"filename,omitempty"` // Archive the version was unpacked from
+	Checksum    string    `json:"checksum,omitempty"` // SHA-256 (hex) or go.sum "h1:" hash of the archive
+	Cached      bool      `json:"cached,omitempty"`   // The archive came from the download cache, verified against Source
+	Ref         string    `json:"ref,omitempty"`      // Git ref built from source
+	Commit      string    `json:"commit,omitempty"`   // Git commit built from source
+	OS          string    `json:"os"`
+	Arch        string    `json:"arch"`
+	InstalledAt time.Time `json:"installed_at"`
+	SgvVersion  string    `json:"sgv_version"`
+}
+
+// Path returns the location of the metadata of an installed version.
+func Path(goVersion string) string {
+	return filepath.Join(config.VersionsDir, goVersion, FileName)
+}
+
+// Load reads the metadata file at path.
+func Load(path string) (*Metadata, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var m Metadata
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &m, nil
+}
+
+// Save writes the metadata to path.
+func (m *Metadata) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode install metadata: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write install metadata: %w", err)
+	}
+	return nil
+}
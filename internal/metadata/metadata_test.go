@@ -0,0 +1,42 @@
+package metadata
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), FileName)
+	want := &Metadata{
+		Version:     "go1.22.1",
+		Method:      MethodDownload,
+		Source:      "https://go.dev/dl/",
+		Filename:    "go1.22.1.linux-amd64.tar.gz",
+		Checksum:    "aab8e15785c997ae20f9c88422ee35d962c4562212bb0f879d052a35c8307c7f",
+		OS:          "linux",
+		Arch:        "amd64",
+		InstalledAt: time.Date(2024, 3, 5, 10, 0, 0, 0, time.UTC),
+		SgvVersion:  "v1.0.0",
+	}
+	if err := want.Save(path); err != nil {
+		t.Fatalf("Save failed: %v", err)
+	}
+
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+
+	if err := os.WriteFile(path, []byte("{"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Load(path); err == nil {
+		t.Error("expected an error for a malformed metadata file")
+	}
+}
@@ -0,0 +1,226 @@
+package mirror
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/installer"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// IndexFile is the name of the release index inside a mirror directory. It has the
+// same format as go.dev/dl/?mode=json&include=all.
+const IndexFile = "index.json"
+
+// SyncOptions selects what Sync downloads.
+type SyncOptions struct {
+	Dir       string   // Mirror directory
+	Versions  []string // Exact versions ("1.22.6") or minor wildcards ("1.21.x")
+	Platforms []string // OS/arch pairs, e.g. "linux/amd64"
+}
+
+// Sync downloads the archives selected by opts from the configured download source
+// into opts.Dir and merges them into the mirror's index. Archives already present
+// with a matching checksum are not downloaded again. It returns the synced files.
+func Sync(opts SyncOptions) ([]version.GoVersionFile, error) {
+	platforms, err := parsePlatforms(opts.Platforms)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Versions) == 0 {
+		return nil, fmt.Errorf("no versions given")
+	}
+
+	releases, err := version.FetchReleases()
+	if err != nil {
+		return nil, err
+	}
+
+	var selected []version.GoVersionResponse
+	for _, pattern := range opts.Versions {
+		found := false
+		for _, release := range releases {
+			if !matchVersion(pattern, release) {
+				continue
+			}
+			found = true
+
+			var files []version.GoVersionFile
+			for _, file := range release.Files {
+				if file.Kind == "archive" && strings.HasSuffix(file.Filename, ".tar.gz") && platforms[file.OS+"/"+file.Arch] {
+					// The name comes from the upstream index and becomes a path in the mirror
+					if !isPlainFilename(file.Filename) {
+						return nil, fmt.Errorf("invalid archive name %q in the release index", file.Filename)
+					}
+					files = append(files, file)
+				}
+			}
+			if len(files) > 0 {
+				release.Files = files
+				selected = append(selected, release)
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("no Go release matches %q", pattern)
+		}
+	}
+
+	if err := os.MkdirAll(opts.Dir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create mirror directory: %w", err)
+	}
+
+	var synced []version.GoVersionFile
+	for _, release := range selected {
+		for i, file := range release.Files {
+			checksum, err := installer.DownloadArchive(file, filepath.Join(opts.Dir, file.Filename))
+			if err != nil {
+				return synced, fmt.Errorf("failed to sync %s: %w", file.Filename, err)
+			}
+			// Record the checksum the archive was verified against, as the upstream
+			// index may have none (e.g., checksum sidecars or a directory source)
+			release.Files[i].SHA256 = checksum
+			synced = append(synced, release.Files[i])
+		}
+	}
+	if len(synced) == 0 {
+		return nil, fmt.Errorf("no archives found for the requested versions and platforms")
+	}
+
+	index, err := ReadIndex(opts.Dir)
+	if err != nil {
+		return synced, err
+	}
+	if err := writeIndex(opts.Dir, mergeReleases(index, selected)); err != nil {
+		return synced, err
+	}
+	return synced, nil
+}
+
+// ReadIndex reads the release index of the mirror in dir. A missing index yields no releases.
+func ReadIndex(dir string) ([]version.GoVersionResponse, error) {
+	data, err := os.ReadFile(filepath.Join(dir, IndexFile))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read mirror index: %w", err)
+	}
+
+	var releases []version.GoVersionResponse
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse mirror index: %w", err)
+	}
+	return releases, nil
+}
+
+// Handler serves a mirror directory the way go.dev/dl/ does: the release index is
+// returned for "?mode=json" requests, everything else is served as static files.
+func Handler(dir string) http.Handler {
+	files := http.FileServer(http.Dir(dir))
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" && r.URL.Query().Get("mode") == "json" {
+			w.Header().Set("Content-Type", "application/json")
+			http.ServeFile(w, r, filepath.Join(dir, IndexFile))
+			return
+		}
+		files.ServeHTTP(w, r)
+	})
+}
+
+// writeIndex atomically writes the release index of the mirror in dir.
+func writeIndex(dir string, releases []version.GoVersionResponse) error {
+	data, err := json.MarshalIndent(releases, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode mirror index: %w", err)
+	}
+
+	indexPath := filepath.Join(dir, IndexFile)
+	tmpPath := indexPath + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write mirror index: %w", err)
+	}
+	if err := os.Rename(tmpPath, indexPath); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("failed to write mirror index: %w", err)
+	}
+	return nil
+}
+
+// mergeReleases adds the files of added to index, newest release first.
+func mergeReleases(index, added []version.GoVersionResponse) []version.GoVersionResponse {
+	byVersion := make(map[string]*version.GoVersionResponse)
+	var merged []*version.GoVersionResponse
+	for _, release := range append(index, added...) {
+		existing, ok := byVersion[release.Version]
+		if !ok {
+			r := release
+			r.Files = nil
+			existing = &r
+			byVersion[release.Version] = existing
+			merged = append(merged, existing)
+		}
+		for _, file := range release.Files {
+			replaced := false
+			for i := range existing.Files {
+				if existing.Files[i].Filename == file.Filename {
+					existing.Files[i] = file
+					replaced = true
+				}
+			}
+			if !replaced {
+				existing.Files = append(existing.Files, file)
+			}
+		}
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		return version.Compare(merged[i].Version, merged[j].Version) > 0
+	})
+
+	result := make([]version.GoVersionResponse, 0, len(merged))
+	for _, r := range merged {
+		sort.Slice(r.Files, func(i, j int) bool { return r.Files[i].Filename < r.Files[j].Filename })
+		result = append(result, *r)
+	}
+	return result
+}
+
+// matchVersion reports whether release is selected by pattern. Minor wildcards
+// such as "1.21.x" select all stable releases of that minor version.
+func matchVersion(pattern string, release version.GoVersionResponse) bool {
+	pattern = "go" + strings.TrimPrefix(pattern, "go")
+	if minor, ok := strings.CutSuffix(pattern, ".x"); ok {
+		return release.Stable && (release.Version == minor || strings.HasPrefix(release.Version, minor+"."))
+	}
+	return release.Version == pattern
+}
+
+// isPlainFilename reports whether name is a file name without any directory part.
+func isPlainFilename(name string) bool {
+	return name != "" && name != "." && filepath.Base(name) == name && !strings.Contains(name, "..")
+}
+
+// parsePlatforms parses "os/arch" pairs into a set.
+func parsePlatforms(platforms []string) (map[string]bool, error) {
+	if len(platforms) == 0 {
+		return nil, fmt.Errorf("no platforms given")
+	}
+
+	set := make(map[string]bool)
+	for _, p := range platforms {
+		goOS, goARCH, ok := strings.Cut(p, "/")
+		if !ok || goOS == "" || goARCH == "" {
+			return nil, fmt.Errorf("invalid platform %q, expected <os>/<arch> such as linux/amd64", p)
+		}
+		if goOS == "windows" {
+			return nil, fmt.Errorf("invalid platform %q: Windows is not supported by sgv", p)
+		}
+		set[goOS+"/"+goARCH] = true
+	}
+	return set, nil
+}
@@ -0,0 +1,227 @@
+package mirror
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// newUpstream starts a fake go.dev/dl serving the given archive names with dummy content.
+func newUpstream(t *testing.T, releases map[string]bool, filenames []string) *httptest.Server {
+	t.Helper()
+
+	var index []version.GoVersionResponse
+	content := make(map[string][]byte)
+	for v, stable := range releases {
+		release := version.GoVersionResponse{Version: v, Stable: stable}
+		for _, name := range filenames {
+			if !strings.HasPrefix(name, v+".") {
+				continue
+			}
+			platform := strings.TrimSuffix(strings.TrimPrefix(name, v+"."), ".tar.gz")
+			goOS, goARCH, _ := strings.Cut(platform, "-")
+			body := []byte("archive " + name)
+			sum := sha256.Sum256(body)
+			content[name] = body
+			release.Files = append(release.Files, version.GoVersionFile{
+				Filename: name, OS: goOS, Arch: goARCH, Version: v,
+				SHA256: hex.EncodeToString(sum[:]), Size: int64(len(body)), Kind: "archive",
+			})
+		}
+		index = append(index, release)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_ = json.NewEncoder(w).Encode(index)
+			return
+		}
+		body, ok := content[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	original := config.DownloadMirrors
+	config.DownloadMirrors = []string{server.URL + "/"}
+	t.Cleanup(func() { config.DownloadMirrors = original })
+	return server
+}
+
+func TestSyncAndServe(t *testing.T) {
+	newUpstream(t,
+		map[string]bool{"go1.21.12": true, "go1.21.13": true, "go1.22.6": true, "go1.23rc1": false},
+		[]string{
+			"go1.21.12.linux-amd64.tar.gz", "go1.21.12.darwin-arm64.tar.gz",
+			"go1.21.13.linux-amd64.tar.gz", "go1.21.13.darwin-arm64.tar.gz",
+			"go1.22.6.linux-amd64.tar.gz", "go1.22.6.linux-arm64.tar.gz",
+			"go1.23rc1.linux-amd64.tar.gz",
+		})
+	dir := t.TempDir()
+
+	synced, err := Sync(SyncOptions{Dir: dir, Versions: []string{"1.21.x"}, Platforms: []string{"linux/amd64"}})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(synced) != 2 {
+		t.Fatalf("expected 2 synced files, got %d", len(synced))
+	}
+
+	// A second sync adds to the existing index
+	if _, err := Sync(SyncOptions{Dir: dir, Versions: []string{"go1.22.6"}, Platforms: []string{"linux/amd64", "linux/arm64"}}); err != nil {
+		t.Fatalf("second Sync failed: %v", err)
+	}
+
+	index, err := ReadIndex(dir)
+	if err != nil {
+		t.Fatalf("ReadIndex failed: %v", err)
+	}
+	var got []string
+	for _, r := range index {
+		got = append(got, r.Version)
+	}
+	if strings.Join(got, ",") != "go1.22.6,go1.21.13,go1.21.12" {
+		t.Errorf("unexpected index versions %v", got)
+	}
+	if len(index[0].Files) != 2 {
+		t.Errorf("expected 2 files for go1.22.6, got %d", len(index[0].Files))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "go1.21.13.darwin-arm64.tar.gz")); !os.IsNotExist(err) {
+		t.Errorf("unrequested platform was synced")
+	}
+
+	server := httptest.NewServer(Handler(dir))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL + "/?mode=json&include=all")
+	if err != nil {
+		t.Fatalf("GET index: %v", err)
+	}
+	var served []version.GoVersionResponse
+	if err := json.NewDecoder(resp.Body).Decode(&served); err != nil {
+		t.Fatalf("decode served index: %v", err)
+	}
+	resp.Body.Close()
+	if len(served) != 3 {
+		t.Errorf("expected 3 served releases, got %d", len(served))
+	}
+
+	resp, err = http.Get(server.URL + "/go1.22.6.linux-arm64.tar.gz")
+	if err != nil {
+		t.Fatalf("GET archive: %v", err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if string(body) != "archive go1.22.6.linux-arm64.tar.gz" {
+		t.Errorf("unexpected archive content %q", body)
+	}
+}
+
+func TestSyncErrors(t *testing.T) {
+	newUpstream(t, map[string]bool{"go1.22.6": true}, []string{"go1.22.6.linux-amd64.tar.gz"})
+
+	tests := []struct {
+		name string
+		opts SyncOptions
+	}{
+		{"unknown version", SyncOptions{Versions: []string{"1.19.x"}, Platforms: []string{"linux/amd64"}}},
+		{"invalid platform", SyncOptions{Versions: []string{"1.22.x"}, Platforms: []string{"linux"}}},
+		{"windows platform", SyncOptions{Versions: []string{"1.22.x"}, Platforms: []string{"windows/amd64"}}},
+		{"no matching archives", SyncOptions{Versions: []string{"1.22.x"}, Platforms: []string{"freebsd/amd64"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opts.Dir = t.TempDir()
+			if _, err := Sync(tt.opts); err == nil {
+				t.Errorf("expected Sync to fail")
+			}
+		})
+	}
+}
+
+// serveUpstream starts a fake go.dev/dl serving index and the files in content.
+func serveUpstream(t *testing.T, index []version.GoVersionResponse, content map[string][]byte) {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_ = json.NewEncoder(w).Encode(index)
+			return
+		}
+		body, ok := content[strings.TrimPrefix(r.URL.Path, "/")]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	original := config.DownloadMirrors
+	config.DownloadMirrors = []string{server.URL + "/"}
+	t.Cleanup(func() { config.DownloadMirrors = original })
+}
+
+func TestSyncRecordsVerifiedChecksum(t *testing.T) {
+	const name = "go1.22.6.linux-amd64.tar.gz"
+	body := []byte("archive " + name)
+	sum := sha256.Sum256(body)
+	checksum := hex.EncodeToString(sum[:])
+
+	// The index lists no checksum; it is only published as a sidecar
+	serveUpstream(t, []version.GoVersionResponse{{
+		Version: "go1.22.6", Stable: true,
+		Files: []version.GoVersionFile{{Filename: name, OS: "linux", Arch: "amd64", Version: "go1.22.6", Kind: "archive"}},
+	}}, map[string][]byte{name: body, name + ".sha256": []byte(checksum + "  " + name + "\n")})
+	dir := t.TempDir()
+
+	synced, err := Sync(SyncOptions{Dir: dir, Versions: []string{"1.22.6"}, Platforms: []string{"linux/amd64"}})
+	if err != nil {
+		t.Fatalf("Sync failed: %v", err)
+	}
+	if len(synced) != 1 || synced[0].SHA256 != checksum {
+		t.Errorf("synced %+v, want checksum %s", synced, checksum)
+	}
+
+	index, err := ReadIndex(dir)
+	if err != nil {
+		t.Fatalf("ReadIndex failed: %v", err)
+	}
+	if len(index) != 1 || len(index[0].Files) != 1 || index[0].Files[0].SHA256 != checksum {
+		t.Errorf("index %+v, want checksum %s", index, checksum)
+	}
+}
+
+func TestSyncRejectsUnsafeArchiveNames(t *testing.T) {
+	for _, name := range []string{"../go1.22.6.linux-amd64.tar.gz", "sub/go1.22.6.linux-amd64.tar.gz", "/tmp/go1.22.6.linux-amd64.tar.gz"} {
+		t.Run(name, func(t *testing.T) {
+			serveUpstream(t, []version.GoVersionResponse{{
+				Version: "go1.22.6", Stable: true,
+				Files: []version.GoVersionFile{{Filename: name, OS: "linux", Arch: "amd64", Version: "go1.22.6", Kind: "archive"}},
+			}}, map[string][]byte{name: []byte("archive")})
+			root := t.TempDir()
+			dir := filepath.Join(root, "mirror")
+
+			if _, err := Sync(SyncOptions{Dir: dir, Versions: []string{"1.22.6"}, Platforms: []string{"linux/amd64"}}); err == nil {
+				t.Fatalf("expected Sync to reject %q", name)
+			}
+			if _, err := os.Stat(filepath.Join(root, "go1.22.6.linux-amd64.tar.gz")); !os.IsNotExist(err) {
+				t.Errorf("archive was written outside the mirror directory")
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+// Package progress renders several progress bars at once, one line per task.
+package progress
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/term"
+)
+
+const (
+	refreshInterval = 150 * time.Millisecond
+	barWidth        = 25
+)
+
+// Multi draws one line per Bar and redraws them in place while tasks run. When the
+// output is not a terminal nothing is redrawn; each bar prints a single line when
+// it is done instead, which keeps CI logs readable.
+type Multi struct {
+	out         io.Writer
+	interactive bool
+
+	mu    sync.Mutex
+	bars  []*Bar
+	drawn int // Number of lines drawn by the last redraw
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// NewMulti starts a display writing to out.
+func NewMulti(out io.Writer) *Multi {
+	m := &Multi{
+		out:  out,
+		stop: make(chan struct{}),
+		done: make(chan struct{}),
+	}
+	if f, ok := out.(*os.File); ok {
+		m.interactive = term.IsTerminal(int(f.Fd()))
+	}
+
+	go m.loop()
+	return m
+}
+
+// Add appends a bar labelled name.
+func (m *Multi) Add(name string) *Bar {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	b := &Bar{multi: m, name: name, total: -1, status: "waiting"}
+	m.bars = append(m.bars, b)
+	return b
+}
+
+// Stop draws the final state of all bars and stops redrawing.
+func (m *Multi) Stop() {
+	close(m.stop)
+	<-m.done
+}
+
+func (m *Multi) loop() {
+	defer close(m.done)
+
+	ticker := time.NewTicker(refreshInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			m.redraw()
+		case <-m.stop:
+			m.redraw()
+			return
+		}
+	}
+}
+
+// redraw moves the cursor back over the previously drawn lines and draws every bar again.
+func (m *Multi) redraw() {
+	if !m.interactive {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var sb strings.Builder
+	if m.drawn > 0 {
+		fmt.Fprintf(&sb, "\033[%dA", m.drawn)
+	}
+	for _, b := range m.bars {
+		sb.WriteString("\033[2K")
+		sb.WriteString(b.line())
+		sb.WriteByte('\n')
+	}
+	m.drawn = len(m.bars)
+	_, _ = io.WriteString(m.out, sb.String())
+}
+
+// Bar tracks a single task. Downloaded bytes are written to it, status messages to
+// the writer returned by Status.
+type Bar struct {
+	multi *Multi
+	name  string
+
+	// Guarded by multi.mu
+	current  int64
+	total    int64
+	status   string
+	finished bool
+}
+
+// Start resets the bar for a download of total bytes (-1 if unknown), of which
+// offset bytes are already present. It returns the bar as the writer to copy the
+// downloaded data to.
+func (b *Bar) Start(total, offset int64) io.Writer {
+	b.multi.mu.Lock()
+	defer b.multi.mu.Unlock()
+
+	b.total, b.current = total, offset
+	return b
+}
+
+// Write counts downloaded bytes.
+func (b *Bar) Write(p []byte) (int, error) {
+	b.multi.mu.Lock()
+	defer b.multi.mu.Unlock()
+
+	b.current += int64(len(p))
+	return len(p), nil
+}
+
+// Status returns a writer whose messages replace the status text shown next to the
+// bar. Only the last non-empty line of each message is kept.
+func (b *Bar) Status() io.Writer {
+	return statusWriter{b}
+}
+
+// Done marks the task as finished with a final status message.
+func (b *Bar) Done(status string) {
+	b.multi.mu.Lock()
+	b.status, b.finished = status, true
+	line := b.line()
+	b.multi.mu.Unlock()
+
+	if !b.multi.interactive {
+		_, _ = fmt.Fprintln(b.multi.out, line)
+	}
+}
+
+// line renders the bar. The caller must hold multi.mu.
+func (b *Bar) line() string {
+	if b.finished || b.total <= 0 {
+		if !b.finished && b.current > 0 {
+			return fmt.Sprintf("%-12s %s  %s", b.name, formatBytes(b.current), b.status)
+		}
+		return fmt.Sprintf("%-12s %s", b.name, b.status)
+	}
+
+	filled := int(min(b.current, b.total) * barWidth / b.total)
+	return fmt.Sprintf("%-12s [%s%s] %3d%% %s/%s  %s",
+		b.name,
+		strings.Repeat("=", filled), strings.Repeat(" ", barWidth-filled),
+		b.current*100/b.total, formatBytes(b.current), formatBytes(b.total),
+		b.status)
+}
+
+type statusWriter struct {
+	b *Bar
+}
+
+func (w statusWriter) Write(p []byte) (int, error) {
+	lines := strings.Split(strings.TrimSpace(string(p)), "\n")
+	if msg := strings.TrimSpace(lines[len(lines)-1]); msg != "" {
+		w.b.multi.mu.Lock()
+		w.b.status = msg
+		w.b.multi.mu.Unlock()
+	}
+	return len(p), nil
+}
+
+// formatBytes formats n bytes in human readable binary units.
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
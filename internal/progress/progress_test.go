@@ -0,0 +1,57 @@
+package progress
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestBarLine(t *testing.T) {
+	m := NewMulti(&bytes.Buffer{})
+	defer m.Stop()
+
+	b := m.Add("go1.22.1")
+	if got := b.line(); got != "go1.22.1     waiting" {
+		t.Errorf("initial line = %q", got)
+	}
+
+	fmt.Fprintf(b.Status(), "Downloading go1.22.1.linux-amd64.tar.gz\n")
+	w := b.Start(4096, 1024)
+	_, _ = w.Write(make([]byte, 1024))
+	want := "go1.22.1     [" + strings.Repeat("=", 12) + strings.Repeat(" ", 13) + "]  50% 2.0 KiB/4.0 KiB  Downloading go1.22.1.linux-amd64.tar.gz"
+	if got := b.line(); got != want {
+		t.Errorf("line = %q, want %q", got, want)
+	}
+}
+
+func TestNonInteractiveOutput(t *testing.T) {
+	var out bytes.Buffer
+	m := NewMulti(&out)
+
+	a := m.Add("go1.21.13")
+	b := m.Add("go1.22.6")
+	fmt.Fprintln(a.Status(), "Extracting\nstill extracting")
+	b.Done("failed: boom")
+	a.Done("installed")
+	m.Stop()
+
+	// Without a terminal, only the final state of each bar is printed, in completion order
+	want := "go1.22.6     failed: boom\ngo1.21.13    installed\n"
+	if got := out.String(); got != want {
+		t.Errorf("output = %q, want %q", got, want)
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	tests := map[int64]string{
+		512:              "512 B",
+		1536:             "1.5 KiB",
+		75 * 1024 * 1024: "75.0 MiB",
+	}
+	for n, want := range tests {
+		if got := formatBytes(n); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
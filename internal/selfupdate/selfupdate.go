@@ -0,0 +1,217 @@
+// Package selfupdate replaces the running sgv binary with the latest release.
+//
+// Releases are described by a release index in the format of the GitHub "latest
+// release" API: a JSON object with the release's tag_name and its assets. Each
+// platform has an archive named sgv_<version>_<os>_<arch>.tar.gz holding the sgv
+// binary, whose SHA-256 is taken from the asset's digest or from a
+// <archive>.sha256 asset next to it.
+package selfupdate
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/httpclient"
+
+	"golang.org/x/mod/semver"
+)
+
+// Release is the newest sgv release listed by a release index.
+type Release struct {
+	Version string  `json:"tag_name"` // e.g. v1.4.0
+	Assets  []Asset `json:"assets"`
+}
+
+// Asset is a file attached to a release.
+type Asset struct {
+	Name   string `json:"name"`
+	URL    string `json:"browser_download_url"`
+	Digest string `json:"digest,omitempty"` // "sha256:<hex>", if the index provides it
+}
+
+// Latest reads the release index at url.
+func Latest(url string) (*Release, error) {
+	data, err := get(url, 1<<20)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release index %s: %w", url, err)
+	}
+
+	var r Release
+	if err := json.Unmarshal(data, &r); err != nil {
+		return nil, fmt.Errorf("failed to parse release index %s: %w", url, err)
+	}
+	if !semver.IsValid(r.Version) {
+		return nil, fmt.Errorf("release index %s lists no valid release version (got %q)", url, r.Version)
+	}
+	return &r, nil
+}
+
+// Newer reports whether the release is newer than current, the version of the
+// running sgv. Development builds are older than any release.
+func (r *Release) Newer(current string) bool {
+	return semver.Compare(r.Version, current) > 0
+}
+
+// ArchiveName returns the name of the release archive for goos/goarch.
+func (r *Release) ArchiveName(goos, goarch string) string {
+	return fmt.Sprintf("sgv_%s_%s_%s.tar.gz", strings.TrimPrefix(r.Version, "v"), goos, goarch)
+}
+
+// Archive returns the archive asset for goos/goarch.
+func (r *Release) Archive(goos, goarch string) (Asset, error) {
+	name := r.ArchiveName(goos, goarch)
+	if a, ok := r.asset(name); ok {
+		return a, nil
+	}
+	return Asset{}, fmt.Errorf("sgv %s has no archive for %s/%s (%s)", r.Version, goos, goarch, name)
+}
+
+// Checksum returns the hex SHA-256 of the archive a, from its digest or from the
+// <name>.sha256 asset. A release without either cannot be verified and is refused.
+func (r *Release) Checksum(a Asset) (string, error) {
+	if hash, ok := strings.CutPrefix(a.Digest, "sha256:"); ok {
+		return strings.ToLower(hash), nil
+	}
+
+	sidecar, ok := r.asset(a.Name + ".sha256")
+	if !ok {
+		return "", fmt.Errorf("sgv %s publishes no checksum for %s", r.Version, a.Name)
+	}
+	data, err := get(sidecar.URL, 4096)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch checksum of %s: %w", a.Name, err)
+	}
+	// sha256sum format: "<hex>  <filename>"
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != sha256.Size*2 {
+		return "", fmt.Errorf("malformed checksum file %s", sidecar.Name)
+	}
+	return strings.ToLower(fields[0]), nil
+}
+
+func (r *Release) asset(name string) (Asset, bool) {
+	for _, a := range r.Assets {
+		if a.Name == name {
+			return a, true
+		}
+	}
+	return Asset{}, false
+}
+
+// Apply downloads the archive a, verifies it against checksum and atomically
+// replaces the binary at exe with the sgv binary inside it. Nothing is changed if
+// any step fails.
+func Apply(a Asset, checksum, exe string) error {
+	dir := filepath.Dir(exe)
+	archive, err := os.CreateTemp(dir, ".sgv-update-*.tar.gz")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	defer os.Remove(archive.Name())
+	defer archive.Close()
+
+	if err := download(a.URL, archive, checksum); err != nil {
+		return fmt.Errorf("failed to download %s: %w", a.Name, err)
+	}
+	if _, err := archive.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	binary, err := os.CreateTemp(dir, ".sgv-update-*")
+	if err != nil {
+		return fmt.Errorf("cannot write to %s: %w", dir, err)
+	}
+	defer os.Remove(binary.Name())
+	if err := extractBinary(archive, binary); err != nil {
+		binary.Close()
+		return fmt.Errorf("failed to extract %s: %w", a.Name, err)
+	}
+	if err := binary.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(binary.Name(), 0755); err != nil {
+		return err
+	}
+
+	// The temporary file is in the same directory, so this replaces exe atomically
+	if err := os.Rename(binary.Name(), exe); err != nil {
+		return fmt.Errorf("failed to replace %s: %w", exe, err)
+	}
+	return nil
+}
+
+// download writes url to out and checks its SHA-256 against checksum.
+func download(url string, out io.Writer, checksum string) error {
+	client, err := httpclient.DownloadClient()
+	if err != nil {
+		return err
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+
+	h := sha256.New()
+	if _, err := io.Copy(io.MultiWriter(out, h), resp.Body); err != nil {
+		return err
+	}
+	if got := hex.EncodeToString(h.Sum(nil)); got != checksum {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", checksum, got)
+	}
+	return nil
+}
+
+// extractBinary copies the "sgv" entry of the .tar.gz archive r to out.
+func extractBinary(r io.Reader, out io.Writer) error {
+	gz, err := gzip.NewReader(r)
+	if err != nil {
+		return err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			return errors.New("archive does not contain the sgv binary")
+		}
+		if err != nil {
+			return err
+		}
+		if header.Typeflag == tar.TypeReg && filepath.Base(header.Name) == "sgv" {
+			_, err := io.Copy(out, tr)
+			return err
+		}
+	}
+}
+
+// get reads a small document of at most limit bytes from url.
+func get(url string, limit int64) ([]byte, error) {
+	client, err := httpclient.Client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected HTTP status %s", resp.Status)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, limit))
+}
@@ -0,0 +1,157 @@
+package selfupdate
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newReleaseServer serves a release index for v1.5.0 with a linux/amd64 archive
+// holding binary. With sidecar the checksum is published as a .sha256 asset,
+// otherwise as the asset digest.
+func newReleaseServer(t *testing.T, binary string, sidecar bool) *httptest.Server {
+	t.Helper()
+
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	if err := tw.WriteHeader(&tar.Header{Name: "sgv", Mode: 0755, Size: int64(len(binary)), Typeflag: tar.TypeReg}); err != nil {
+		t.Fatalf("write tar header: %v", err)
+	}
+	tw.Write([]byte(binary))
+	tw.Close()
+	gz.Close()
+	archive := buf.Bytes()
+	sum := sha256.Sum256(archive)
+	checksum := hex.EncodeToString(sum[:])
+
+	mux := http.NewServeMux()
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	name := "sgv_1.5.0_linux_amd64.tar.gz"
+	release := Release{Version: "v1.5.0", Assets: []Asset{{Name: name, URL: server.URL + "/" + name}}}
+	if sidecar {
+		release.Assets = append(release.Assets, Asset{Name: name + ".sha256", URL: server.URL + "/" + name + ".sha256"})
+	} else {
+		release.Assets[0].Digest = "sha256:" + checksum
+	}
+
+	mux.HandleFunc("/latest", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(release)
+	})
+	mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+		w.Write(archive)
+	})
+	mux.HandleFunc("/"+name+".sha256", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(checksum + "  " + name + "\n"))
+	})
+	return server
+}
+
+func TestLatest(t *testing.T) {
+	server := newReleaseServer(t, "new sgv", false)
+
+	r, err := Latest(server.URL + "/latest")
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	if r.Version != "v1.5.0" {
+		t.Errorf("Version = %q, want v1.5.0", r.Version)
+	}
+
+	tests := []struct {
+		current string
+		want    bool
+	}{
+		{"v1.4.2", true},
+		{"v1.5.0", false},
+		{"v1.6.0", false},
+		{"v0.0.0-20260101000000-abcdef123456", true},
+		{"dev", true},
+	}
+	for _, tt := range tests {
+		if got := r.Newer(tt.current); got != tt.want {
+			t.Errorf("Newer(%q) = %v, want %v", tt.current, got, tt.want)
+		}
+	}
+
+	if _, err := r.Archive("plan9", "386"); err == nil || !strings.Contains(err.Error(), "sgv_1.5.0_plan9_386.tar.gz") {
+		t.Errorf("expected an error naming the missing archive, got %v", err)
+	}
+}
+
+func TestApply(t *testing.T) {
+	for _, sidecar := range []bool{false, true} {
+		server := newReleaseServer(t, "new sgv", sidecar)
+		r, err := Latest(server.URL + "/latest")
+		if err != nil {
+			t.Fatalf("Latest failed: %v", err)
+		}
+		asset, err := r.Archive("linux", "amd64")
+		if err != nil {
+			t.Fatalf("Archive failed: %v", err)
+		}
+		checksum, err := r.Checksum(asset)
+		if err != nil {
+			t.Fatalf("Checksum failed (sidecar %v): %v", sidecar, err)
+		}
+
+		dir := t.TempDir()
+		exe := filepath.Join(dir, "sgv")
+		if err := os.WriteFile(exe, []byte("old sgv"), 0755); err != nil {
+			t.Fatalf("write: %v", err)
+		}
+		if err := Apply(asset, checksum, exe); err != nil {
+			t.Fatalf("Apply failed: %v", err)
+		}
+
+		data, err := os.ReadFile(exe)
+		if err != nil || string(data) != "new sgv" {
+			t.Errorf("binary = %q, %v; want the new release", data, err)
+		}
+		if fi, err := os.Stat(exe); err != nil || fi.Mode().Perm() != 0755 {
+			t.Errorf("binary is not executable: %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+			t.Errorf("expected temporary files to be removed, found %d entries", len(entries))
+		}
+	}
+}
+
+func TestApplyChecksumMismatch(t *testing.T) {
+	server := newReleaseServer(t, "new sgv", false)
+	r, err := Latest(server.URL + "/latest")
+	if err != nil {
+		t.Fatalf("Latest failed: %v", err)
+	}
+	asset, _ := r.Archive("linux", "amd64")
+
+	exe := filepath.Join(t.TempDir(), "sgv")
+	if err := os.WriteFile(exe, []byte("old sgv"), 0755); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	err = Apply(asset, strings.Repeat("0", 64), exe)
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+	if data, _ := os.ReadFile(exe); string(data) != "old sgv" {
+		t.Errorf("binary was replaced despite the checksum mismatch")
+	}
+}
+
+func TestChecksumMissing(t *testing.T) {
+	r := &Release{Version: "v1.5.0", Assets: []Asset{{Name: "sgv_1.5.0_linux_amd64.tar.gz"}}}
+	if _, err := r.Checksum(r.Assets[0]); err == nil {
+		t.Error("expected a release without checksum to be refused")
+	}
+}
@@ -0,0 +1,113 @@
+package source
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// indexFile is the release index 'sgv mirror sync' writes into a mirror directory.
+const indexFile = "index.json"
+
+// archiveNameRegex matches the names of Go release archives, e.g. go1.22.1.linux-amd64.tar.gz.
+var archiveNameRegex = regexp.MustCompile(`^(go\d+\.\d+(?:\.\d+)?((?:rc|beta)\d+)?)\.([a-z0-9]+)-([a-z0-9]+)\.tar\.gz$`)
+
+func init() {
+	Register("dir", func(arg string) (Source, error) {
+		if arg == "" {
+			return nil, fmt.Errorf("dir needs a directory path")
+		}
+		return NewDir(arg), nil
+	})
+}
+
+// dir is a local directory of Go archives, such as a mirror populated by
+// 'sgv mirror sync' or a network share. Releases are read from its index.json if
+// present and derived from the archive names otherwise.
+type dir struct {
+	path string
+}
+
+// NewDir returns a source for the archives in the directory at path.
+func NewDir(path string) Source {
+	return &dir{path: path}
+}
+
+func (s *dir) Name() string {
+	return s.path
+}
+
+func (s *dir) Releases() ([]Release, error) {
+	indexPath := filepath.Join(s.path, indexFile)
+	if _, err := os.Stat(indexPath); err == nil {
+		return readReleases(indexPath)
+	}
+
+	entries, err := os.ReadDir(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %w", err)
+	}
+
+	var releases []Release
+	byVersion := make(map[string]int)
+	for _, entry := range entries {
+		m := archiveNameRegex.FindStringSubmatch(entry.Name())
+		if m == nil || entry.IsDir() {
+			continue
+		}
+
+		i, ok := byVersion[m[1]]
+		if !ok {
+			i = len(releases)
+			byVersion[m[1]] = i
+			releases = append(releases, Release{Version: m[1], Stable: m[2] == ""})
+		}
+
+		var size int64
+		if fi, err := entry.Info(); err == nil {
+			size = fi.Size()
+		}
+		releases[i].Files = append(releases[i].Files, File{
+			Filename: entry.Name(),
+			OS:       m[3],
+			Arch:     m[4],
+			Version:  m[1],
+			Size:     size,
+			Kind:     "archive",
+		})
+	}
+	return releases, nil
+}
+
+// Checksum returns the checksum from the index or a <filename>.sha256 file. Archives
+// without either are trusted as they are, since the directory is under local control.
+func (s *dir) Checksum(file File) (string, error) {
+	if file.SHA256 != "" {
+		return file.SHA256, nil
+	}
+
+	archivePath := filepath.Join(s.path, file.Filename)
+	if _, err := os.Stat(archivePath + ".sha256"); err == nil {
+		return readChecksumFile(archivePath + ".sha256")
+	}
+
+	f, err := os.Open(archivePath)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", archivePath, err)
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+func (s *dir) Open(file File, offset int64) (*Archive, error) {
+	return openFile(filepath.Join(s.path, file.Filename), offset)
+}
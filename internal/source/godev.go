@@ -0,0 +1,65 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	Register("godev", func(arg string) (Source, error) {
+		if !isURL(arg) {
+			return nil, fmt.Errorf("godev needs an http(s) URL, got %q", arg)
+		}
+		return NewGoDev(arg), nil
+	})
+}
+
+// goDev is a go.dev/dl compatible download site: the release index is served at
+// <base>?mode=json&include=all and archives at <base><filename>.
+type goDev struct {
+	base string
+}
+
+// NewGoDev returns a source for the go.dev/dl compatible download site at base.
+func NewGoDev(base string) Source {
+	if !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	return &goDev{base: base}
+}
+
+func (s *goDev) Name() string {
+	return s.base
+}
+
+func (s *goDev) Releases() ([]Release, error) {
+	return readReleases(s.base + "?mode=json&include=all")
+}
+
+// Checksum returns the checksum from the release index, falling back to the
+// <filename>.sha256 file go.dev publishes next to every archive.
+func (s *goDev) Checksum(file File) (string, error) {
+	if file.SHA256 != "" {
+		return file.SHA256, nil
+	}
+	return readChecksumFile(s.base + file.Filename + ".sha256")
+}
+
+func (s *goDev) Open(file File, offset int64) (*Archive, error) {
+	return open(s.base+file.Filename, offset)
+}
+
+// readChecksumFile reads a hex encoded SHA-256 from a <file>.sha256 document,
+// which may be followed by the file name as in sha256sum output.
+func readChecksumFile(location string) (string, error) {
+	data, err := readAll(location)
+	if err != nil {
+		return "", fmt.Errorf("no checksum published for %s: %w", location, err)
+	}
+
+	fields := strings.Fields(string(data))
+	if len(fields) == 0 || len(fields[0]) != 64 {
+		return "", fmt.Errorf("malformed checksum file %s", location)
+	}
+	return strings.ToLower(fields[0]), nil
+}
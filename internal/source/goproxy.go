@@ -0,0 +1,114 @@
+package source
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+// ToolchainModule is the module the go command downloads toolchains from.
+const ToolchainModule = "golang.org/toolchain"
+
+// toolchainVersionPrefix precedes the Go version in toolchain module versions.
+const toolchainVersionPrefix = "v0.0.1-"
+
+func init() {
+	Register("goproxy", func(arg string) (Source, error) {
+		if arg == "" {
+			arg = config.GoProxy
+		}
+		if !isURL(arg) {
+			return nil, fmt.Errorf("goproxy needs an http(s) URL, got %q", arg)
+		}
+		return NewGoProxy(arg), nil
+	})
+}
+
+// goProxy serves releases published as golang.org/toolchain modules (Go 1.21 and
+// later) on a Go module proxy. The zips are verified against their go.sum hash
+// from the checksum database configured in config.GoSumDB.
+type goProxy struct {
+	base string
+}
+
+// NewGoProxy returns a source for the Go module proxy at base.
+func NewGoProxy(base string) Source {
+	return &goProxy{base: strings.TrimSuffix(base, "/")}
+}
+
+func (s *goProxy) Name() string {
+	return s.base
+}
+
+// Releases lists the toolchain module versions known to the proxy.
+func (s *goProxy) Releases() ([]Release, error) {
+	data, err := readAll(s.base + "/" + ToolchainModule + "/@v/list")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list toolchain versions: %w", err)
+	}
+
+	var releases []Release
+	byVersion := make(map[string]int)
+	for _, modVersion := range strings.Fields(string(data)) {
+		file, ok := parseToolchainVersion(modVersion)
+		if !ok {
+			continue
+		}
+
+		i, ok := byVersion[file.Version]
+		if !ok {
+			i = len(releases)
+			byVersion[file.Version] = i
+			stable := !strings.Contains(file.Version, "rc") && !strings.Contains(file.Version, "beta")
+			releases = append(releases, Release{Version: file.Version, Stable: stable})
+		}
+		releases[i].Files = append(releases[i].Files, file)
+	}
+	return releases, nil
+}
+
+func (s *goProxy) Checksum(file File) (string, error) {
+	modVersion := strings.TrimSuffix(file.Filename, ".zip")
+	return lookupModuleHash(ToolchainModule, modVersion)
+}
+
+func (s *goProxy) Open(file File, offset int64) (*Archive, error) {
+	return openURL(s.base+"/"+ToolchainModule+"/@v/"+file.Filename, offset)
+}
+
+// ToolchainFile describes the toolchain module zip holding goVersion for the given
+// platform, e.g. v0.0.1-go1.22.1.linux-amd64.zip.
+func ToolchainFile(goVersion, goOS, goARCH string) File {
+	return File{
+		Filename: toolchainVersionPrefix + goVersion + "." + goOS + "-" + goARCH + ".zip",
+		OS:       goOS,
+		Arch:     goARCH,
+		Version:  goVersion,
+		Kind:     "archive",
+	}
+}
+
+// ModulePrefix returns the directory prefix every entry of a toolchain module zip
+// carries, e.g. golang.org/toolchain@v0.0.1-go1.22.1.linux-amd64/.
+func ModulePrefix(file File) string {
+	return ToolchainModule + "@" + strings.TrimSuffix(file.Filename, ".zip") + "/"
+}
+
+// parseToolchainVersion parses a toolchain module version such as
+// v0.0.1-go1.22.1.linux-amd64.
+func parseToolchainVersion(modVersion string) (File, bool) {
+	rest, ok := strings.CutPrefix(modVersion, toolchainVersionPrefix)
+	if !ok {
+		return File{}, false
+	}
+	dot := strings.LastIndex(rest, ".")
+	if dot < 0 {
+		return File{}, false
+	}
+	goOS, goARCH, ok := strings.Cut(rest[dot+1:], "-")
+	if !ok || !strings.HasPrefix(rest, "go") {
+		return File{}, false
+	}
+	return ToolchainFile(rest[:dot], goOS, goARCH), true
+}
@@ -0,0 +1,52 @@
+package source
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	Register("index", func(arg string) (Source, error) {
+		if arg == "" {
+			return nil, fmt.Errorf("index needs the URL or path of an index file")
+		}
+		return NewIndex(arg), nil
+	})
+}
+
+// index is a static release index file in the go.dev JSON format, e.g. one written
+// by 'sgv mirror sync' and uploaded to an artifact repository. Archives are
+// expected next to the index file.
+type index struct {
+	location string
+	base     string
+}
+
+// NewIndex returns a source for the index file at location, a URL or local path.
+func NewIndex(location string) Source {
+	base := filepath.Dir(location) + string(filepath.Separator)
+	if isURL(location) {
+		base = location[:strings.LastIndex(location, "/")+1]
+	}
+	return &index{location: location, base: base}
+}
+
+func (s *index) Name() string {
+	return s.location
+}
+
+func (s *index) Releases() ([]Release, error) {
+	return readReleases(s.location)
+}
+
+func (s *index) Checksum(file File) (string, error) {
+	if file.SHA256 != "" {
+		return file.SHA256, nil
+	}
+	return readChecksumFile(s.base + file.Filename + ".sha256")
+}
+
+func (s *index) Open(file File, offset int64) (*Archive, error) {
+	return open(s.base+file.Filename, offset)
+}
@@ -0,0 +1,257 @@
+// Package source abstracts where Go releases are listed and downloaded from.
+//
+// A Source lists releases, serves their archives and knows the checksum each
+// archive must match. The sources sgv uses are selected with the SGV_SOURCE setting,
+// a comma separated list of "<name>" or "<name>:<argument>" entries that are tried in
+// order. Additional source types can be added with Register.
+package source
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/httpclient"
+)
+
+// Release is a Go release in the format served by go.dev/dl/?mode=json&include=all.
+type Release struct {
+	Version string `json:"version"`
+	Stable  bool   `json:"stable"`
+	Files   []File `json:"files"`
+}
+
+// File is a single download of a release.
+type File struct {
+	Filename string `json:"filename"`
+	OS       string `json:"os"`
+	Arch     string `json:"arch"`
+	Version  string `json:"version"`
+	SHA256   string `json:"sha256"`
+	Size     int64  `json:"size"`
+	Kind     string `json:"kind"`
+}
+
+// Source lists Go releases and serves their archives.
+type Source interface {
+	// Name identifies the source in messages, e.g. by its URL.
+	Name() string
+	// Releases returns all releases the source offers, including their files.
+	Releases() ([]Release, error)
+	// Checksum returns the checksum the archive must match: a hex encoded SHA-256,
+	// or a go.sum style "h1:" hash for module zips.
+	Checksum(file File) (string, error)
+	// Open returns the contents of the archive starting at offset. Sources that
+	// cannot resume return the whole archive, reported by an Offset of 0.
+	Open(file File, offset int64) (*Archive, error)
+}
+
+// Archive is an opened archive, possibly starting in the middle of the file.
+type Archive struct {
+	io.ReadCloser
+	Offset int64 // Position of the first byte read from ReadCloser
+	Size   int64 // Total size of the file, or -1 if unknown
+}
+
+// Factory creates a Source from the argument of its SGV_SOURCE entry, which is
+// empty if the entry has none.
+type Factory func(arg string) (Source, error)
+
+var factories = map[string]Factory{}
+
+// Register makes a source type available to SGV_SOURCE under name. It is meant to
+// be called from init functions and panics if name is already registered.
+func Register(name string, factory Factory) {
+	if _, dup := factories[name]; dup {
+		panic("source: Register called twice for " + name)
+	}
+	factories[name] = factory
+}
+
+// Names returns the registered source types, sorted.
+func Names() []string {
+	names := make([]string, 0, len(factories))
+	for name := range factories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Configured returns the sources selected by config.Source in the order they are
+// tried. A "godev" entry without argument expands to one source per download mirror.
+func Configured() ([]Source, error) {
+	return Parse(config.Source)
+}
+
+// Parse creates the sources described by a SGV_SOURCE style spec.
+func Parse(spec string) ([]Source, error) {
+	if strings.TrimSpace(spec) == "" {
+		spec = config.DefaultSource
+	}
+
+	var sources []Source
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, arg, _ := strings.Cut(entry, ":")
+
+		factory, ok := factories[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown source %q in %q (available: %s)", name, spec, strings.Join(Names(), ", "))
+		}
+
+		args := []string{arg}
+		if name == "godev" && arg == "" {
+			args = config.DownloadMirrors
+		}
+		for _, arg := range args {
+			src, err := factory(arg)
+			if err != nil {
+				return nil, fmt.Errorf("invalid source %q: %w", entry, err)
+			}
+			sources = append(sources, src)
+		}
+	}
+
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no download source configured")
+	}
+	return sources, nil
+}
+
+// StatusError is returned when a server answers with an unexpected HTTP status.
+type StatusError struct {
+	Code   int
+	Status string
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("bad HTTP status: %s", e.Status)
+}
+
+// ErrRangeNotSatisfiable signals that a resume request was rejected and the
+// download has to start over.
+var ErrRangeNotSatisfiable = errors.New("server rejected resume request, restarting download")
+
+// isURL reports whether location is an HTTP(S) URL rather than a local path.
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
+
+// open opens a URL or local file starting at offset.
+func open(location string, offset int64) (*Archive, error) {
+	if isURL(location) {
+		return openURL(location, offset)
+	}
+	return openFile(location, offset)
+}
+
+// openURL downloads url starting at offset using an HTTP Range request.
+func openURL(url string, offset int64) (*Archive, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create HTTP request: %w", err)
+	}
+	if offset > 0 {
+		req.Header.Set("Range", "bytes="+strconv.FormatInt(offset, 10)+"-")
+	}
+
+	client, err := httpclient.DownloadClient()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download file: %w", err)
+	}
+
+	size := int64(-1)
+	switch {
+	case resp.StatusCode == http.StatusPartialContent && offset > 0:
+		if resp.ContentLength >= 0 {
+			size = offset + resp.ContentLength
+		}
+		return &Archive{ReadCloser: resp.Body, Offset: offset, Size: size}, nil
+	case resp.StatusCode == http.StatusOK:
+		// Either a fresh download or a server that ignores Range
+		if resp.ContentLength >= 0 {
+			size = resp.ContentLength
+		}
+		return &Archive{ReadCloser: resp.Body, Size: size}, nil
+	case resp.StatusCode == http.StatusRequestedRangeNotSatisfiable:
+		resp.Body.Close()
+		return nil, ErrRangeNotSatisfiable
+	default:
+		resp.Body.Close()
+		return nil, &StatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+}
+
+// openFile opens a local file starting at offset.
+func openFile(path string, offset int64) (*Archive, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fi, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to stat %s: %w", path, err)
+	}
+	if offset > fi.Size() {
+		f.Close()
+		return nil, ErrRangeNotSatisfiable
+	}
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("failed to seek %s: %w", path, err)
+	}
+	return &Archive{ReadCloser: f, Offset: offset, Size: fi.Size()}, nil
+}
+
+// readAll reads a small document, such as a release index, from a URL or local file.
+func readAll(location string) ([]byte, error) {
+	if !isURL(location) {
+		return os.ReadFile(location)
+	}
+
+	client, err := httpclient.Client()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Get(location)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode, Status: resp.Status}
+	}
+	return io.ReadAll(resp.Body)
+}
+
+// readReleases reads a release index in the go.dev JSON format.
+func readReleases(location string) ([]Release, error) {
+	data, err := readAll(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch release index: %w", err)
+	}
+
+	var releases []Release
+	if err := json.Unmarshal(data, &releases); err != nil {
+		return nil, fmt.Errorf("failed to parse release index: %w", err)
+	}
+	return releases, nil
+}
@@ -0,0 +1,265 @@
+package source
+
+import (
+	"crypto/rand"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+
+	"golang.org/x/mod/sumdb"
+	"golang.org/x/mod/sumdb/note"
+)
+
+func TestParse(t *testing.T) {
+	original := config.DownloadMirrors
+	config.DownloadMirrors = []string{"https://a.example.com/dl/", "https://b.example.com/dl/"}
+	t.Cleanup(func() { config.DownloadMirrors = original })
+
+	tests := []struct {
+		name    string
+		spec    string
+		want    []string
+		wantErr bool
+	}{
+		{"default expands mirrors", "", []string{"https://a.example.com/dl/", "https://b.example.com/dl/"}, false},
+		{"godev with URL", "godev:https://c.example.com/dl", []string{"https://c.example.com/dl/"}, false},
+		{"several sources", "dir:/srv/go, goproxy:https://athens.example.com/", []string{"/srv/go", "https://athens.example.com"}, false},
+		{"index", "index:https://artifactory.example.com/go/index.json", []string{"https://artifactory.example.com/go/index.json"}, false},
+		{"unknown source", "ftp:example.com", nil, true},
+		{"missing argument", "dir", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := Parse(tt.spec)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			}
+			var names []string
+			for _, src := range sources {
+				names = append(names, src.Name())
+			}
+			if !reflect.DeepEqual(names, tt.want) {
+				t.Errorf("Parse(%q) = %v, want %v", tt.spec, names, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirSource(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"go1.22.1.linux-amd64.tar.gz":        "archive 1",
+		"go1.22.1.darwin-arm64.tar.gz":       "archive 2",
+		"go1.23rc1.linux-amd64.tar.gz":       "archive 3",
+		"go1.22.1.linux-amd64.tar.gz.sha256": "2ec2b4b2c0b0bc0efdf3d1b4a1aabf2a0c4ef1a4e1bd21c3a0d0ed26e3c1b0a1  go1.22.1.linux-amd64.tar.gz\n",
+		"README":                             "not an archive",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+
+	src := NewDir(dir)
+	releases, err := src.Releases()
+	if err != nil {
+		t.Fatalf("Releases failed: %v", err)
+	}
+
+	got := make(map[string]int)
+	for _, r := range releases {
+		got[r.Version] = len(r.Files)
+		if r.Stable != !strings.Contains(r.Version, "rc") {
+			t.Errorf("release %s: stable = %v", r.Version, r.Stable)
+		}
+	}
+	if want := map[string]int{"go1.22.1": 2, "go1.23rc1": 1}; !reflect.DeepEqual(got, want) {
+		t.Errorf("releases = %v, want %v", got, want)
+	}
+
+	// The sidecar checksum file takes precedence over hashing the archive
+	checksum, err := src.Checksum(File{Filename: "go1.22.1.linux-amd64.tar.gz"})
+	if err != nil || checksum != "2ec2b4b2c0b0bc0efdf3d1b4a1aabf2a0c4ef1a4e1bd21c3a0d0ed26e3c1b0a1" {
+		t.Errorf("Checksum = %q, %v", checksum, err)
+	}
+
+	archive, err := src.Open(File{Filename: "go1.22.1.darwin-arm64.tar.gz"}, 3)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer archive.Close()
+	data, _ := io.ReadAll(archive)
+	if string(data) != "hive 2" || archive.Offset != 3 || archive.Size != 9 {
+		t.Errorf("Open returned %q at offset %d of %d", data, archive.Offset, archive.Size)
+	}
+}
+
+func TestIndexSourceResolvesArchivesNextToIndex(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/go/index.json", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"version":"go1.22.1","stable":true,"files":[{"filename":"go1.22.1.linux-amd64.tar.gz","os":"linux","arch":"amd64","version":"go1.22.1","sha256":"abc","kind":"archive"}]}]`))
+	})
+	mux.HandleFunc("/go/go1.22.1.linux-amd64.tar.gz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("archive"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	src := NewIndex(server.URL + "/go/index.json")
+	releases, err := src.Releases()
+	if err != nil {
+		t.Fatalf("Releases failed: %v", err)
+	}
+	if len(releases) != 1 || len(releases[0].Files) != 1 {
+		t.Fatalf("unexpected releases %+v", releases)
+	}
+
+	archive, err := src.Open(releases[0].Files[0], 0)
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	defer archive.Close()
+	if data, _ := io.ReadAll(archive); string(data) != "archive" {
+		t.Errorf("Open returned %q", data)
+	}
+}
+
+func TestGoProxySource(t *testing.T) {
+	const hash = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/golang.org/toolchain/@v/list", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("v0.0.1-go1.22.1.linux-amd64\nv0.0.1-go1.22.1.darwin-arm64\nv0.0.1-go1.23rc2.linux-amd64\nv0.0.1-unknown\n"))
+	})
+	sumdbHandler, vkey := newTestSumDB(t, map[string]string{"golang.org/toolchain@v0.0.1-go1.22.1.linux-amd64": hash})
+	mux.Handle("/sumdb/", http.StripPrefix("/sumdb", sumdbHandler))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	original := config.GoSumDB
+	config.GoSumDB = vkey + " " + server.URL + "/sumdb"
+	t.Cleanup(func() { config.GoSumDB = original })
+
+	src := NewGoProxy(server.URL + "/")
+	releases, err := src.Releases()
+	if err != nil {
+		t.Fatalf("Releases failed: %v", err)
+	}
+	if len(releases) != 2 {
+		t.Fatalf("expected 2 releases, got %+v", releases)
+	}
+	if releases[0].Version != "go1.22.1" || !releases[0].Stable || len(releases[0].Files) != 2 {
+		t.Errorf("unexpected release %+v", releases[0])
+	}
+	if releases[1].Version != "go1.23rc2" || releases[1].Stable {
+		t.Errorf("unexpected release %+v", releases[1])
+	}
+
+	file := ToolchainFile("go1.22.1", "linux", "amd64")
+	if file != releases[0].Files[0] {
+		t.Errorf("ToolchainFile = %+v, listed %+v", file, releases[0].Files[0])
+	}
+	if got := ModulePrefix(file); got != "golang.org/toolchain@v0.0.1-go1.22.1.linux-amd64/" {
+		t.Errorf("ModulePrefix = %q", got)
+	}
+
+	checksum, err := src.Checksum(file)
+	if err != nil || checksum != hash {
+		t.Errorf("Checksum = %q, %v; want %q", checksum, err, hash)
+	}
+}
+
+// newTestSumDB returns a signed checksum database recording the module zip hashes
+// in hashes, keyed by "<path>@<version>", and its verifier key.
+func newTestSumDB(t *testing.T, hashes map[string]string) (http.Handler, string) {
+	t.Helper()
+	skey, vkey, err := note.GenerateKey(rand.Reader, "sumdb.test")
+	if err != nil {
+		t.Fatalf("generate sumdb key: %v", err)
+	}
+
+	handler := sumdb.NewServer(sumdb.NewTestServer(skey, func(path, vers string) ([]byte, error) {
+		hash, ok := hashes[path+"@"+vers]
+		if !ok {
+			return nil, fmt.Errorf("%s@%s not found", path, vers)
+		}
+		return []byte(path + " " + vers + " " + hash + "\n" +
+			path + " " + vers + "/go.mod h1:AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=\n"), nil
+	}))
+	return handler, vkey
+}
+
+func TestLookupModuleHashRejectsUnverifiedRecords(t *testing.T) {
+	const (
+		path    = "golang.org/toolchain"
+		version = "v0.0.1-go1.22.1.linux-amd64"
+		hash    = "h1:47DEQpj8HBSa+/TImW+5JCeuQeRkm5NMpJWZG3hSuFU="
+	)
+
+	signed, vkey := newTestSumDB(t, map[string]string{path + "@" + version: hash})
+	// The same record, signed with another key than the configured one
+	other, _ := newTestSumDB(t, map[string]string{path + "@" + version: hash})
+
+	mux := http.NewServeMux()
+	mux.Handle("/signed/", http.StripPrefix("/signed", signed))
+	mux.Handle("/other/", http.StripPrefix("/other", other))
+	// A plain record without a signed tree, as a proxy could forge it
+	mux.HandleFunc("/unsigned/lookup/"+path+"@"+version, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("42\n" + path + " " + version + " " + hash + "\n"))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	original := config.GoSumDB
+	t.Cleanup(func() { config.GoSumDB = original })
+
+	config.GoSumDB = vkey + " " + server.URL + "/signed"
+	if got, err := lookupModuleHash(path, version); err != nil || got != hash {
+		t.Fatalf("lookupModuleHash = %q, %v; want %q", got, err, hash)
+	}
+
+	for _, dir := range []string{"/other", "/unsigned"} {
+		config.GoSumDB = vkey + " " + server.URL + dir
+		if got, err := lookupModuleHash(path, version); err == nil {
+			t.Errorf("lookupModuleHash via %s = %q, want an error", dir, got)
+		}
+	}
+}
+
+func TestParseGoSumDB(t *testing.T) {
+	const golangKey = "sum.golang.org+033de0ae+Ac4zctda0e5eza+HJyk9SxEdh+s3Ux18htTTAD8OuAn8"
+
+	tests := []struct {
+		setting string
+		key     string
+		url     string
+		wantErr bool
+	}{
+		{setting: "sum.golang.org", key: golangKey, url: "https://sum.golang.org"},
+		{setting: "sum.golang.google.cn", key: golangKey, url: "https://sum.golang.google.cn"},
+		{setting: "sum.golang.org https://proxy.example.com/sumdb/sum.golang.org/", key: golangKey, url: "https://proxy.example.com/sumdb/sum.golang.org"},
+		{setting: "sumdb.example.com+12345678+AAAA", key: "sumdb.example.com+12345678+AAAA", url: "https://sumdb.example.com"},
+		{setting: "sumdb.example.com", wantErr: true},
+		{setting: "off", wantErr: true},
+		{setting: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		key, url, err := parseGoSumDB(tt.setting)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseGoSumDB(%q) error = %v, wantErr %v", tt.setting, err, tt.wantErr)
+			continue
+		}
+		if key != tt.key || url != tt.url {
+			t.Errorf("parseGoSumDB(%q) = %q, %q; want %q, %q", tt.setting, key, url, tt.key, tt.url)
+		}
+	}
+}
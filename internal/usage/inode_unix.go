@@ -0,0 +1,17 @@
+//go:build unix
+
+package usage
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// hardLinkID identifies the inode of fi if it has more than one hard link.
+func hardLinkID(fi fs.FileInfo) ([2]uint64, bool) {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok || st.Nlink <= 1 {
+		return [2]uint64{}, false
+	}
+	return [2]uint64{uint64(st.Dev), uint64(st.Ino)}, true
+}
@@ -0,0 +1,149 @@
+// Package usage reports how much disk space sgv uses.
+package usage
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/fun7257/sgv/internal/cache"
+	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/env"
+	"github.com/fun7257/sgv/internal/version"
+)
+
+// Entry is the size of one installed version or env file.
+type Entry struct {
+	Name string
+	Path string
+	Size int64
+}
+
+// Report lists the disk space used below config.SgvRoot.
+type Report struct {
+	Versions []Entry // Installed Go versions, oldest first
+	EnvFiles []Entry // Per-version env files, oldest version first
+	Cache    int64   // Download cache and version index
+	Shared   int64   // Bytes of hard linked files counted under more than one version
+}
+
+// Total returns the combined size of everything in the report. Files hard linked
+// between versions (see sgv dedupe) are counted once.
+func (r *Report) Total() int64 {
+	total := r.Cache - r.Shared
+	for _, e := range r.Versions {
+		total += e.Size
+	}
+	for _, e := range r.EnvFiles {
+		total += e.Size
+	}
+	return total
+}
+
+// Collect measures the installed versions, env files and download cache.
+func Collect() (*Report, error) {
+	r := &Report{}
+
+	versions, err := os.ReadDir(config.VersionsDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read versions directory: %w", err)
+	}
+	seen := make(map[[2]uint64]bool)
+	for _, v := range versions {
+		if !v.IsDir() {
+			continue
+		}
+		path := filepath.Join(config.VersionsDir, v.Name())
+		size, shared, err := dirSize(path, seen)
+		if err != nil {
+			return nil, err
+		}
+		r.Shared += shared
+		r.Versions = append(r.Versions, Entry{Name: v.Name(), Path: path, Size: size})
+	}
+
+	envFiles, err := os.ReadDir(env.GetEnvDir())
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read env directory: %w", err)
+	}
+	for _, f := range envFiles {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), ".env") {
+			continue
+		}
+		fi, err := f.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat env file %s: %w", f.Name(), err)
+		}
+		r.EnvFiles = append(r.EnvFiles, Entry{
+			Name: strings.TrimSuffix(f.Name(), ".env"),
+			Path: filepath.Join(env.GetEnvDir(), f.Name()),
+			Size: fi.Size(),
+		})
+	}
+
+	if r.Cache, err = cache.Size(); err != nil {
+		return nil, err
+	}
+
+	sortByVersion(r.Versions)
+	sortByVersion(r.EnvFiles)
+	return r, nil
+}
+
+// DirSize returns the total size of the regular files below dir. Symlinks are not followed.
+func DirSize(dir string) (int64, error) {
+	size, _, err := dirSize(dir, make(map[[2]uint64]bool))
+	return size, err
+}
+
+// dirSize returns the total size of the regular files below dir, and how much of it
+// belongs to hard linked files whose device and inode numbers were already in seen.
+func dirSize(dir string, seen map[[2]uint64]bool) (size, shared int64, err error) {
+	err = filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		fi, err := d.Info()
+		if err != nil {
+			return err
+		}
+		size += fi.Size()
+		if id, ok := hardLinkID(fi); ok {
+			if seen[id] {
+				shared += fi.Size()
+			}
+			seen[id] = true
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to measure %s: %w", dir, err)
+	}
+	return size, shared, nil
+}
+
+// SortBySize orders entries largest first, breaking ties by name.
+func SortBySize(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if entries[i].Size != entries[j].Size {
+			return entries[i].Size > entries[j].Size
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
+
+// sortByVersion orders entries named after Go versions oldest first.
+func sortByVersion(entries []Entry) {
+	sort.SliceStable(entries, func(i, j int) bool {
+		if c := version.Compare(entries[i].Name, entries[j].Name); c != 0 {
+			return c < 0
+		}
+		return entries[i].Name < entries[j].Name
+	})
+}
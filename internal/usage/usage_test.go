@@ -0,0 +1,119 @@
+package usage
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/fun7257/sgv/internal/config"
+)
+
+func writeFile(t *testing.T, path string, size int) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.WriteFile(path, make([]byte, size), 0644); err != nil {
+		t.Fatalf("write %s: %v", path, err)
+	}
+}
+
+func TestCollect(t *testing.T) {
+	tmp := t.TempDir()
+	originalRoot, originalVersions, originalCache := config.SgvRoot, config.VersionsDir, config.CacheDir
+	config.SgvRoot = tmp
+	config.VersionsDir = filepath.Join(tmp, "versions")
+	config.CacheDir = filepath.Join(tmp, "cache")
+	t.Cleanup(func() {
+		config.SgvRoot, config.VersionsDir, config.CacheDir = originalRoot, originalVersions, originalCache
+	})
+
+	writeFile(t, filepath.Join(tmp, "versions", "go1.9.7", "go", "bin", "go"), 300)
+	writeFile(t, filepath.Join(tmp, "versions", "go1.10.8", "go", "bin", "go"), 100)
+	writeFile(t, filepath.Join(tmp, "versions", "go1.10.8", "go", "VERSION"), 20)
+	// Symlinks are not followed, so the target is not counted twice
+	if err := os.Symlink(filepath.Join(tmp, "versions", "go1.9.7", "go", "bin", "go"), filepath.Join(tmp, "versions", "go1.10.8", "go", "link")); err != nil {
+		t.Fatalf("symlink: %v", err)
+	}
+	writeFile(t, filepath.Join(tmp, "env", "go1.10.8.env"), 7)
+	writeFile(t, filepath.Join(tmp, "cache", "downloads", "abc", "go1.10.8.linux-amd64.tar.gz"), 50)
+
+	r, err := Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+
+	wantVersions := []Entry{
+		{Name: "go1.9.7", Path: filepath.Join(tmp, "versions", "go1.9.7"), Size: 300},
+		{Name: "go1.10.8", Path: filepath.Join(tmp, "versions", "go1.10.8"), Size: 120},
+	}
+	if !reflect.DeepEqual(r.Versions, wantVersions) {
+		t.Errorf("Versions = %+v, want %+v", r.Versions, wantVersions)
+	}
+	if len(r.EnvFiles) != 1 || r.EnvFiles[0].Name != "go1.10.8" || r.EnvFiles[0].Size != 7 {
+		t.Errorf("EnvFiles = %+v", r.EnvFiles)
+	}
+	if r.Cache != 50 {
+		t.Errorf("Cache = %d, want 50", r.Cache)
+	}
+	if r.Total() != 477 {
+		t.Errorf("Total = %d, want 477", r.Total())
+	}
+
+	SortBySize(r.Versions)
+	if r.Versions[0].Name != "go1.9.7" {
+		t.Errorf("SortBySize put %s first", r.Versions[0].Name)
+	}
+}
+
+func TestCollectWithoutInstalls(t *testing.T) {
+	tmp := t.TempDir()
+	originalRoot, originalVersions, originalCache := config.SgvRoot, config.VersionsDir, config.CacheDir
+	config.SgvRoot = tmp
+	config.VersionsDir = filepath.Join(tmp, "versions")
+	config.CacheDir = filepath.Join(tmp, "cache")
+	t.Cleanup(func() {
+		config.SgvRoot, config.VersionsDir, config.CacheDir = originalRoot, originalVersions, originalCache
+	})
+
+	r, err := Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if len(r.Versions) != 0 || len(r.EnvFiles) != 0 || r.Total() != 0 {
+		t.Errorf("expected an empty report, got %+v", r)
+	}
+}
+
+func TestCollectCountsHardLinksOnce(t *testing.T) {
+	tmp := t.TempDir()
+	originalRoot, originalVersions, originalCache := config.SgvRoot, config.VersionsDir, config.CacheDir
+	config.SgvRoot = tmp
+	config.VersionsDir = filepath.Join(tmp, "versions")
+	config.CacheDir = filepath.Join(tmp, "cache")
+	t.Cleanup(func() {
+		config.SgvRoot, config.VersionsDir, config.CacheDir = originalRoot, originalVersions, originalCache
+	})
+
+	original := filepath.Join(tmp, "versions", "go1.22.0", "go", "src", "fmt", "print.go")
+	linked := filepath.Join(tmp, "versions", "go1.22.1", "go", "src", "fmt", "print.go")
+	writeFile(t, original, 100)
+	if err := os.MkdirAll(filepath.Dir(linked), 0755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	if err := os.Link(original, linked); err != nil {
+		t.Fatalf("link: %v", err)
+	}
+
+	r, err := Collect()
+	if err != nil {
+		t.Fatalf("Collect failed: %v", err)
+	}
+	if r.Versions[0].Size != 100 || r.Versions[1].Size != 100 {
+		t.Errorf("Versions = %+v, want 100 bytes each", r.Versions)
+	}
+	if r.Shared != 100 || r.Total() != 100 {
+		t.Errorf("Shared = %d, Total = %d, want 100 and 100", r.Shared, r.Total())
+	}
+}
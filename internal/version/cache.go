@@ -1,11 +1,14 @@
 package version
 
 import (
+	"crypto/sha256"
 	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
 	"time"
+
+	"github.com/fun7257/sgv/internal/config"
 )
 
 // VersionCache handles caching of Go version data
@@ -14,10 +17,17 @@ type VersionCache struct {
 	cacheDuration time.Duration
 }
 
-// NewVersionCache creates a new version cache instance
+// NewVersionCache creates a new version cache instance. Each download source
+// configuration gets its own cache file, since sources list different files.
 func NewVersionCache() *VersionCache {
+	name := "remote-versions.json"
+	if config.Source != "" && config.Source != config.DefaultSource {
+		sum := sha256.Sum256([]byte(config.Source))
+		name = fmt.Sprintf("remote-versions-%x.json", sum[:4])
+	}
+
 	return &VersionCache{
-		cacheFile:     filepath.Join(os.TempDir(), "sgv-remote-versions-cache.json"),
+		cacheFile:     filepath.Join(config.CacheDir, name),
 		cacheDuration: time.Hour,
 	}
 }
@@ -74,9 +84,20 @@ func (c *VersionCache) Save(versions []GoVersion) {
 		return // Ignore directory creation errors
 	}
 
-	// Write to temporary file first, then rename for atomic operation
-	tempFile := c.cacheFile + ".tmp"
-	if err := os.WriteFile(tempFile, data, 0644); err != nil {
+	// Write to a temporary file first, then rename for atomic operation. The name
+	// is unique so that concurrent installs never write to the same file.
+	tmp, err := os.CreateTemp(filepath.Dir(c.cacheFile), filepath.Base(c.cacheFile)+".tmp*")
+	if err != nil {
+		return // Ignore write errors
+	}
+	tempFile := tmp.Name()
+	_ = tmp.Chmod(0644)
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(tempFile)
 		return // Ignore write errors
 	}
 
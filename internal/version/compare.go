@@ -0,0 +1,71 @@
+package version
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// DevelPrefix is the name prefix of toolchains built from source, e.g. "gotip-1a2b3c4d5e".
+const DevelPrefix = "gotip-"
+
+// goVersionRegex matches Go release names without the "go" prefix, e.g. "1.22.1",
+// "1.20", "1.23rc1" or "1.21beta2".
+var goVersionRegex = regexp.MustCompile(`^(\d+)\.(\d+)(?:\.(\d+))?(?:(rc|beta)(\d+))?$`)
+
+// ToSemver converts a Go version name into a semantic version for comparison:
+//
+//	go1.22.1  -> v1.22.1
+//	go1.20    -> v1.20.0
+//	go1.23rc1 -> v1.23.0-rc.1
+//
+// The "go" prefix is optional. It returns an empty string if v is not a Go version.
+func ToSemver(v string) string {
+	m := goVersionRegex.FindStringSubmatch(strings.TrimPrefix(v, "go"))
+	if m == nil {
+		return ""
+	}
+
+	patch := m[3]
+	if patch == "" {
+		patch = "0"
+	}
+	s := "v" + m[1] + "." + m[2] + "." + patch
+	if m[4] != "" {
+		s += "-" + m[4] + "." + m[5]
+	}
+	return s
+}
+
+// IsValid reports whether v is a well-formed Go version name.
+func IsValid(v string) bool {
+	return ToSemver(v) != ""
+}
+
+// IsPrerelease reports whether v is a release candidate or beta, such as go1.23rc1.
+func IsPrerelease(v string) bool {
+	return semver.Prerelease(ToSemver(v)) != ""
+}
+
+// Compare compares two Go version names, returning -1, 0 or +1. Prereleases sort
+// before the release they precede (go1.23rc2 < go1.23.0), and invalid versions
+// sort before all valid ones.
+func Compare(a, b string) int {
+	return semver.Compare(ToSemver(a), ToSemver(b))
+}
+
+// MajorVersion returns the major version (in Go terms, e.g. "1.22") that v belongs to,
+// or an empty string if v is not a Go version.
+func MajorVersion(v string) string {
+	m := goVersionRegex.FindStringSubmatch(strings.TrimPrefix(v, "go"))
+	if m == nil {
+		return ""
+	}
+	return m[1] + "." + m[2]
+}
+
+// IsDevel reports whether name refers to a toolchain built from source rather than a release.
+func IsDevel(name string) bool {
+	return strings.HasPrefix(name, DevelPrefix) && len(name) > len(DevelPrefix)
+}
@@ -0,0 +1,83 @@
+package version
+
+import "testing"
+
+func TestToSemver(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"go1.22.1", "v1.22.1"},
+		{"1.22.1", "v1.22.1"},
+		{"go1.20", "v1.20.0"},
+		{"go1.21.0", "v1.21.0"},
+		{"go1.23rc1", "v1.23.0-rc.1"},
+		{"go1.24rc2", "v1.24.0-rc.2"},
+		{"go1.21beta2", "v1.21.0-beta.2"},
+		{"go1.13", "v1.13.0"},
+		{"go", ""},
+		{"gotip", ""},
+		{"go1", ""},
+		{"go1.22.1.2", ""},
+		{"go1.23-rc1", ""},
+		{"v1.22.1", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := ToSemver(tt.input); got != tt.want {
+				t.Errorf("ToSemver(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompare(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"go1.22.1", "go1.22.1", 0},
+		{"go1.22.1", "go1.22.10", -1},
+		{"go1.23rc1", "go1.23rc2", -1},
+		{"go1.23rc2", "go1.23.0", -1},
+		{"go1.23beta1", "go1.23rc1", -1},
+		{"go1.23rc1", "go1.22.6", 1},
+		{"go1.20", "go1.20.1", -1},
+		{"go1.21rc4", "go1.20.14", 1},
+		{"invalid", "go1.13", -1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+"_"+tt.b, func(t *testing.T) {
+			if got := Compare(tt.a, tt.b); got != tt.want {
+				t.Errorf("Compare(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsPrereleaseAndMajorVersion(t *testing.T) {
+	tests := []struct {
+		input      string
+		prerelease bool
+		major      string
+	}{
+		{"go1.22.1", false, "1.22"},
+		{"go1.23rc1", true, "1.23"},
+		{"1.21beta2", true, "1.21"},
+		{"go1.20", false, "1.20"},
+		{"gotip-abc1234", false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			if got := IsPrerelease(tt.input); got != tt.prerelease {
+				t.Errorf("IsPrerelease(%q) = %v, want %v", tt.input, got, tt.prerelease)
+			}
+			if got := MajorVersion(tt.input); got != tt.major {
+				t.Errorf("MajorVersion(%q) = %q, want %q", tt.input, got, tt.major)
+			}
+		})
+	}
+}
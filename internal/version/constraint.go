@@ -0,0 +1,271 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/mod/semver"
+)
+
+// Constraint is a set of Go versions described by an expression such as:
+//
+//	1             any Go 1 release (also 1.x, 1.*, ~1 or ^1; >1 starts at 2.0.0)
+//	1.22          any 1.22 release (also 1.22.x or 1.22.*)
+//	1.22.6        exactly go1.22.6 (the "go" prefix is optional everywhere)
+//	~1.21         1.21.0 up to, but excluding, 1.22.0 (~1.21.3 starts at 1.21.3)
+//	^1.21         1.21.0 up to, but excluding, 2.0.0
+//	>=1.21 <1.23  every comparison must hold (>, >=, <, <= and =; space or comma separated)
+//	1.20 || ~1.22 either side may match
+//
+// Release candidates and betas only match if the expression names one itself, e.g.
+// ">=1.23rc1".
+type Constraint struct {
+	text         string
+	alternatives [][]comparison
+	prerelease   bool // Prereleases may match
+}
+
+// comparison is a single term of a constraint.
+type comparison struct {
+	op      string // "=", ">", ">=", "<", "<=" or "minor" (same major.minor version)
+	version string // Semantic version, or "1.22" for "minor"
+}
+
+// ParseConstraint parses a version constraint expression.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{text: strings.TrimSpace(s)}
+	if c.text == "" {
+		return nil, fmt.Errorf("empty version constraint")
+	}
+
+	for _, alt := range strings.Split(c.text, "||") {
+		terms := strings.FieldsFunc(alt, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' })
+		if len(terms) == 0 {
+			return nil, fmt.Errorf("invalid version constraint %q: empty alternative", s)
+		}
+
+		var comparisons []comparison
+		for i := 0; i < len(terms); i++ {
+			term := terms[i]
+			// Allow a space between an operator and its version, as in ">= 1.21"
+			if strings.Trim(term, "<>=~^") == "" && i+1 < len(terms) {
+				i++
+				term += terms[i]
+			}
+
+			parsed, err := c.parseTerm(term)
+			if err != nil {
+				return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+			}
+			comparisons = append(comparisons, parsed...)
+		}
+		c.alternatives = append(c.alternatives, comparisons)
+	}
+	return c, nil
+}
+
+// parseTerm turns one term of a constraint into comparisons.
+func (c *Constraint) parseTerm(term string) ([]comparison, error) {
+	if term == "*" || term == "x" {
+		return nil, nil // Any version
+	}
+
+	op := ""
+	for _, prefix := range []string{">=", "<=", "==", ">", "<", "=", "~", "^"} {
+		if rest, ok := strings.CutPrefix(term, prefix); ok {
+			op, term = prefix, rest
+			break
+		}
+	}
+	if op == "==" {
+		op = "="
+	}
+
+	v := strings.TrimPrefix(term, "go")
+	if rest, ok := strings.CutSuffix(v, ".x"); ok {
+		v = rest
+	} else if rest, ok := strings.CutSuffix(v, ".*"); ok {
+		v = rest
+	} else if m := goVersionRegex.FindStringSubmatch(v); op == "" && m != nil && (m[3] != "" || m[4] != "") {
+		op = "=" // A complete version or a prerelease stands for itself
+	}
+
+	if major, err := strconv.Atoi(v); err == nil && major > 0 {
+		return majorComparisons(op, major), nil
+	}
+
+	sv := ToSemver(v)
+	if sv == "" {
+		return nil, fmt.Errorf("%q is not a Go version", term)
+	}
+	if semver.Prerelease(sv) != "" {
+		c.prerelease = true
+	}
+
+	minor := MajorVersion(v)
+	switch op {
+	case "":
+		return []comparison{{"minor", minor}}, nil
+	case "~":
+		return []comparison{{">=", sv}, {"<", ToSemver(nextMinor(minor))}}, nil
+	case "^":
+		major, _ := strconv.Atoi(strings.TrimPrefix(semver.Major(sv), "v"))
+		return []comparison{{">=", sv}, {"<", fmt.Sprintf("v%d.0.0", major+1)}}, nil
+	default:
+		return []comparison{{op, sv}}, nil
+	}
+}
+
+// majorComparisons returns the comparisons of a term naming only a major version,
+// such as "1" or ">=1". The major version stands for all of its releases.
+func majorComparisons(op string, major int) []comparison {
+	first := fmt.Sprintf("v%d.0.0", major)
+	next := fmt.Sprintf("v%d.0.0", major+1)
+	switch op {
+	case ">":
+		return []comparison{{">=", next}}
+	case ">=":
+		return []comparison{{">=", first}}
+	case "<":
+		return []comparison{{"<", first}}
+	case "<=":
+		return []comparison{{"<", next}}
+	default: // "", "=", "~" and "^"
+		return []comparison{{">=", first}, {"<", next}}
+	}
+}
+
+// String returns the constraint as it was written.
+func (c *Constraint) String() string {
+	return c.text
+}
+
+// Exact returns the version an exact constraint such as "1.22.6" or "=go1.22.6"
+// names, and false for every other constraint.
+func (c *Constraint) Exact() (string, bool) {
+	if len(c.alternatives) != 1 || len(c.alternatives[0]) != 1 || c.alternatives[0][0].op != "=" {
+		return "", false
+	}
+	return FromSemver(c.alternatives[0][0].version), true
+}
+
+// WithPrereleases returns a copy of c that also matches prereleases in its range.
+func (c *Constraint) WithPrereleases() *Constraint {
+	copied := *c
+	copied.prerelease = true
+	return &copied
+}
+
+// Match reports whether v satisfies the constraint. Toolchains built from source
+// never do.
+func (c *Constraint) Match(v string) bool {
+	sv := ToSemver(v)
+	if sv == "" || (semver.Prerelease(sv) != "" && !c.prerelease) {
+		return false
+	}
+
+	for _, comparisons := range c.alternatives {
+		if matchAll(comparisons, v, sv) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAll(comparisons []comparison, v, sv string) bool {
+	for _, cmp := range comparisons {
+		var ok bool
+		switch cmp.op {
+		case "minor":
+			ok = MajorVersion(v) == cmp.version
+		case "=":
+			ok = semver.Compare(sv, cmp.version) == 0
+		case ">":
+			ok = semver.Compare(sv, cmp.version) > 0
+		case ">=":
+			ok = semver.Compare(sv, cmp.version) >= 0
+		case "<":
+			ok = semver.Compare(sv, cmp.version) < 0
+		case "<=":
+			ok = semver.Compare(sv, cmp.version) <= 0
+		}
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// Newest returns the newest of versions matching c, or "" if none does.
+func (c *Constraint) Newest(versions []string) string {
+	newest := ""
+	for _, v := range versions {
+		if c.Match(v) && (newest == "" || Compare(v, newest) > 0) {
+			newest = v
+		}
+	}
+	return newest
+}
+
+// ResolveInstalled returns the newest installed version matching c, or "" if none does.
+func ResolveInstalled(c *Constraint) (string, error) {
+	localVersions, err := GetLocalVersions()
+	if err != nil {
+		return "", err
+	}
+	return c.Newest(localVersions), nil
+}
+
+// ResolveRemote returns the newest release matching c that has an archive for the
+// given platform in the remote version index.
+func ResolveRemote(c *Constraint, goOS, goARCH string) (string, error) {
+	versions, err := GetRemoteVersions()
+	if err != nil {
+		return "", err
+	}
+
+	var candidates []string
+	for _, v := range versions {
+		if v.OS == goOS && v.Arch == goARCH && (strings.HasSuffix(v.Filename, ".tar.gz") || strings.HasSuffix(v.Filename, ".zip")) {
+			candidates = append(candidates, v.Version)
+		}
+	}
+	newest := c.Newest(candidates)
+	if newest == "" {
+		return "", fmt.Errorf("no Go release matching %q found for %s/%s", c, goOS, goARCH)
+	}
+	return newest, nil
+}
+
+// FromSemver converts a semantic version produced by ToSemver back into a Go
+// version name, following the names Go releases use:
+//
+//	v1.22.1      -> go1.22.1
+//	v1.20.0      -> go1.20
+//	v1.21.0      -> go1.21.0
+//	v1.23.0-rc.1 -> go1.23rc1
+func FromSemver(sv string) string {
+	base, pre, _ := strings.Cut(strings.TrimPrefix(sv, "v"), "-")
+	parts := strings.Split(base, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+
+	name := "go" + parts[0] + "." + parts[1]
+	if pre != "" {
+		return name + strings.Replace(pre, ".", "", 1)
+	}
+	// Before Go 1.21 the first release of a minor version had no patch number
+	if parts[2] != "0" || semver.Compare(sv, "v1.21.0") >= 0 {
+		name += "." + parts[2]
+	}
+	return name
+}
+
+// nextMinor returns the minor version after minor, e.g. "1.23" for "1.22".
+func nextMinor(minor string) string {
+	major, m, _ := strings.Cut(minor, ".")
+	n, _ := strconv.Atoi(m)
+	return fmt.Sprintf("%s.%d", major, n+1)
+}
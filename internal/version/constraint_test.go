@@ -0,0 +1,103 @@
+package version
+
+import "testing"
+
+func TestConstraintMatch(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{"1.22", []string{"go1.22.0", "go1.22.6"}, []string{"go1.21.13", "go1.23.0", "go1.22rc1", "gotip-1a2b3c4d5e"}},
+		{"go1.22.x", []string{"go1.22.6"}, []string{"go1.23.0"}},
+		{"1", []string{"go1.13", "go1.22.6"}, []string{"go1.23rc1", "go2.0.0", "gotip-1a2b3c4d5e"}},
+		{"go1.x", []string{"go1.21.0"}, []string{"go2.1.0"}},
+		{">=1", []string{"go1.13", "go2.0.0"}, []string{"go1.23rc1"}},
+		{"~1", []string{"go1.13", "go1.23.2"}, []string{"go2.0.0"}},
+		{"^go1", []string{"go1.23.2"}, []string{"go2.0.0"}},
+		{">1", []string{"go2.0.0"}, []string{"go1.23.2"}},
+		{"<=1", []string{"go1.23.2"}, []string{"go2.0.0"}},
+		{"<2", []string{"go1.23.2"}, []string{"go2.0.0"}},
+		{"1.21beta2", []string{"go1.21beta2"}, []string{"go1.21beta1", "go1.21.0"}},
+		{"1.20", []string{"go1.20", "go1.20.14"}, []string{"go1.21.0"}},
+		{"1.22.6", []string{"go1.22.6"}, []string{"go1.22.5", "go1.22.7"}},
+		{"~1.21", []string{"go1.21.0", "go1.21.13"}, []string{"go1.20.14", "go1.22.0"}},
+		{"~1.21.3", []string{"go1.21.3", "go1.21.13"}, []string{"go1.21.2", "go1.22.0"}},
+		{"^1.21", []string{"go1.21.0", "go1.23.2"}, []string{"go1.20.14"}},
+		{">=1.21 <1.23", []string{"go1.21.0", "go1.22.6"}, []string{"go1.20.14", "go1.23.0"}},
+		{">= 1.21, < 1.23", []string{"go1.22.6"}, []string{"go1.23.0"}},
+		{">1.21.5 <=1.22.1", []string{"go1.21.6", "go1.22.1"}, []string{"go1.21.5", "go1.22.2"}},
+		{"1.20 || ~1.22", []string{"go1.20.3", "go1.22.1"}, []string{"go1.21.0"}},
+		{">=1.23rc1", []string{"go1.23rc1", "go1.23rc2", "go1.23.0"}, []string{"go1.22.6", "go1.23beta1"}},
+		{"1.23rc1", []string{"go1.23rc1"}, []string{"go1.23rc2", "go1.23.0"}},
+		{"*", []string{"go1.13", "go1.23.0"}, []string{"go1.23rc1", "gotip-1a2b3c4d5e"}},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+			continue
+		}
+		for _, v := range tt.match {
+			if !c.Match(v) {
+				t.Errorf("%q should match %s", tt.constraint, v)
+			}
+		}
+		for _, v := range tt.noMatch {
+			if c.Match(v) {
+				t.Errorf("%q should not match %s", tt.constraint, v)
+			}
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", "latest", ">=", "1.22 ||", "0", ">=0", "~", "1.22.6.1", "1.23rc", "1.23alpha1", "1.22.6-rc1", "1.22.6r"} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("ParseConstraint(%q) should fail", s)
+		}
+	}
+}
+
+func TestConstraintExactAndNewest(t *testing.T) {
+	tests := []struct {
+		constraint string
+		exact      string
+		newest     string
+	}{
+		{"1.22.6", "go1.22.6", "go1.22.6"},
+		{"=1.20", "go1.20", ""},
+		{"go1.23rc1", "go1.23rc1", ""},
+		{"1.22", "", "go1.22.6"},
+		{"<1.22", "", "go1.21.13"},
+		{"~1.22.7", "", ""},
+	}
+
+	installed := []string{"go1.21.0", "go1.21.13", "go1.22.0", "go1.22.6", "go1.23rc2", "gotip-1a2b3c4d5e"}
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Fatalf("ParseConstraint(%q) failed: %v", tt.constraint, err)
+		}
+		if exact, ok := c.Exact(); exact != tt.exact || ok != (tt.exact != "") {
+			t.Errorf("%q.Exact() = %q, %v; want %q", tt.constraint, exact, ok, tt.exact)
+		}
+		if got := c.Newest(installed); got != tt.newest {
+			t.Errorf("%q.Newest() = %q, want %q", tt.constraint, got, tt.newest)
+		}
+	}
+
+	c, _ := ParseConstraint("1.23")
+	if got := c.WithPrereleases().Newest(installed); got != "go1.23rc2" {
+		t.Errorf("Newest with prereleases = %q, want go1.23rc2", got)
+	}
+}
+
+func TestFromSemver(t *testing.T) {
+	for _, v := range []string{"go1.22.1", "go1.20", "go1.21.0", "go1.23rc1", "go1.21beta2"} {
+		if got := FromSemver(ToSemver(v)); got != v {
+			t.Errorf("FromSemver(ToSemver(%q)) = %q", v, got)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package version
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Symbolic versions, resolved against the stable releases in the remote version index.
+const (
+	Stable    = "stable"    // Newest stable release
+	OldStable = "oldstable" // Newest release of the minor version before stable
+	// LatestSuffix turns a major version into its newest stable patch release, as in "1.22.latest".
+	LatestSuffix = ".latest"
+)
+
+// IsKeyword reports whether s is a symbolic version: "stable", "oldstable" or
+// "<major>.latest" (e.g., "1.22.latest" or "go1.22.latest").
+func IsKeyword(s string) bool {
+	if s == Stable || s == OldStable {
+		return true
+	}
+	major, ok := strings.CutSuffix(strings.TrimPrefix(s, "go"), LatestSuffix)
+	return ok && major != "" && MajorVersion(major) == major
+}
+
+// ResolveKeyword returns the release the symbolic version s currently stands for on
+// the given platform.
+func ResolveKeyword(s, goOS, goARCH string) (string, error) {
+	versions, err := GetStableGoVersions()
+	if err != nil {
+		return "", err
+	}
+	return resolveKeyword(versions, s, goOS, goARCH)
+}
+
+// resolveKeyword resolves s against the stable releases in versions.
+func resolveKeyword(versions []GoVersion, s, goOS, goARCH string) (string, error) {
+	minors := stableMinors(versions, goOS, goARCH)
+
+	var major string
+	switch s {
+	case Stable:
+		if len(minors) > 0 {
+			major = minors[0]
+		}
+	case OldStable:
+		if len(minors) > 1 {
+			major = minors[1]
+		}
+	default:
+		if !IsKeyword(s) {
+			return "", fmt.Errorf("%q is not a symbolic version", s)
+		}
+		major = strings.TrimSuffix(strings.TrimPrefix(s, "go"), LatestSuffix)
+	}
+
+	if latest := latestPatch(versions, major, goOS, goARCH); major != "" && latest != "" {
+		return latest, nil
+	}
+	return "", fmt.Errorf("no stable release for %q found for %s/%s", s, goOS, goARCH)
+}
+
+// stableMinors returns the minor versions with a stable release for the platform in
+// versions, newest first.
+func stableMinors(versions []GoVersion, goOS, goARCH string) []string {
+	var minors []string
+	for _, v := range versions {
+		if v.OS != goOS || v.Arch != goARCH || !IsValid(v.Version) || IsPrerelease(v.Version) {
+			continue
+		}
+		if minor := MajorVersion(v.Version); !slices.Contains(minors, minor) {
+			minors = append(minors, minor)
+		}
+	}
+	sort.Slice(minors, func(i, j int) bool {
+		return Compare("go"+minors[i], "go"+minors[j]) > 0
+	})
+	return minors
+}
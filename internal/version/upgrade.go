@@ -0,0 +1,87 @@
+package version
+
+import (
+	"sort"
+
+	"github.com/samber/lo"
+)
+
+// Line is a minor version (e.g., "1.22") with at least one installed release.
+type Line struct {
+	Minor     string   // Minor version, e.g. "1.22"
+	Installed []string // Installed releases of the minor version, newest first
+	Latest    string   // Newest stable release of the minor version, "" if none is known
+	Supported bool     // The minor version still receives fixes: it is one of the two newest
+}
+
+// supportedMinors is how many of the newest minor versions are supported under the
+// Go release policy.
+const supportedMinors = 2
+
+// Newest returns the newest installed release of the line.
+func (l Line) Newest() string {
+	return l.Installed[0]
+}
+
+// Outdated reports whether a newer stable release than every installed one exists.
+func (l Line) Outdated() bool {
+	return l.Latest != "" && Compare(l.Latest, l.Newest()) > 0
+}
+
+// Superseded returns the installed releases older than Latest.
+func (l Line) Superseded() []string {
+	var superseded []string
+	for _, v := range l.Installed {
+		if l.Latest != "" && Compare(v, l.Latest) < 0 {
+			superseded = append(superseded, v)
+		}
+	}
+	return superseded
+}
+
+// InstalledLines groups the installed releases by minor version, newest minor first,
+// and looks up the newest stable release of each for the given platform in versions.
+// Toolchains built from source belong to no line.
+func InstalledLines(installed []string, versions []GoVersion, goOS, goARCH string) []Line {
+	stable := lo.Filter(versions, func(item GoVersion, _ int) bool {
+		return item.Stable
+	})
+	// Minor versions newer than the oldest supported one (e.g., with only a release
+	// candidate out) are supported as well
+	oldestSupported := ""
+	if minors := stableMinors(stable, goOS, goARCH); len(minors) > 0 {
+		oldestSupported = minors[min(supportedMinors, len(minors))-1]
+	}
+
+	byMinor := make(map[string]*Line)
+	var lines []*Line
+	for _, v := range installed {
+		minor := MajorVersion(v)
+		if IsDevel(v) || minor == "" {
+			continue
+		}
+		line, ok := byMinor[minor]
+		if !ok {
+			line = &Line{
+				Minor:     minor,
+				Latest:    latestPatch(stable, minor, goOS, goARCH),
+				Supported: oldestSupported != "" && Compare("go"+minor, "go"+oldestSupported) >= 0,
+			}
+			byMinor[minor] = line
+			lines = append(lines, line)
+		}
+		line.Installed = append(line.Installed, v)
+	}
+
+	result := make([]Line, 0, len(lines))
+	for _, line := range lines {
+		sort.Slice(line.Installed, func(i, j int) bool {
+			return Compare(line.Installed[i], line.Installed[j]) > 0
+		})
+		result = append(result, *line)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return Compare("go"+result[i].Minor, "go"+result[j].Minor) > 0
+	})
+	return result
+}
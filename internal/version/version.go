@@ -1,18 +1,20 @@
 package version
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
 	"path/filepath"
 	"runtime/debug"
+	"slices"
 	"sort"
 	"strings"
 	"time"
 
+	"github.com/fun7257/sgv/internal/cache"
 	"github.com/fun7257/sgv/internal/config"
+	"github.com/fun7257/sgv/internal/lock"
+	"github.com/fun7257/sgv/internal/source"
 	"github.com/samber/lo"
 )
 
@@ -24,6 +26,22 @@ var (
 
 // GetSGVVersion reads build info and returns SGV's version string.
 func GetSGVVersion() string {
+	readBuildInfo()
+	return fmt.Sprintf("%s (commit: %s, goVersion: %s)", sgvVersion, sgvCommit, goVersion)
+}
+
+// GetSGVReleaseVersion returns SGV's own module version alone, e.g. "v1.4.0", or
+// "dev" for builds that carry none.
+func GetSGVReleaseVersion() string {
+	readBuildInfo()
+	if sgvVersion == "(devel)" {
+		return "dev"
+	}
+	return sgvVersion
+}
+
+// readBuildInfo fills in sgvVersion, sgvCommit and goVersion from the build info.
+func readBuildInfo() {
 	if info, ok := debug.ReadBuildInfo(); ok {
 		if info.Main.Version != "" {
 			sgvVersion = info.Main.Version
@@ -37,7 +55,6 @@ func GetSGVVersion() string {
 
 		goVersion = info.GoVersion
 	}
-	return fmt.Sprintf("%s (commit: %s, goVersion: %s)", sgvVersion, sgvCommit, goVersion)
 }
 
 // GetLocalVersions reads the VersionsDir and returns a sorted list of installed version names.
@@ -73,31 +90,22 @@ func GetCurrentVersion() (string, error) {
 
 // GoVersion represents a simplified Go version for internal use.
 type GoVersion struct {
-	Version string `json:"version"`
-	Stable  bool   `json:"stable"`
-	OS      string `json:"os"`
-	Arch    string `json:"arch"`
-}
-
-// GoVersionFile represents a file download for a specific Go version
-type GoVersionFile struct {
-	Filename string `json:"filename"`
+	Version  string `json:"version"`
+	Stable   bool   `json:"stable"`
 	OS       string `json:"os"`
 	Arch     string `json:"arch"`
-	Version  string `json:"version"`
-	SHA256   string `json:"sha256"`
-	Size     int64  `json:"size"`
-	Kind     string `json:"kind"`
+	Filename string `json:"filename,omitempty"`
+	SHA256   string `json:"sha256,omitempty"`
+	Size     int64  `json:"size,omitempty"`
 }
 
+// GoVersionFile represents a file download for a specific Go version
+type GoVersionFile = source.File
+
 // GoVersionResponse represents the complete API response structure
-type GoVersionResponse struct {
-	Version string          `json:"version"`
-	Stable  bool            `json:"stable"`
-	Files   []GoVersionFile `json:"files"`
-}
+type GoVersionResponse = source.Release
 
-// GetRemoteVersions fetches available Go versions from the official Go website, with a 10-minute file cache.
+// GetRemoteVersions fetches available Go versions from the configured download sources, with a file cache.
 func GetRemoteVersions() ([]GoVersion, error) {
 	cache := NewVersionCache()
 
@@ -122,33 +130,43 @@ func GetRemoteVersions() ([]GoVersion, error) {
 	return versions, nil
 }
 
-// fetchRemoteVersions fetches versions from the official Go website
+// fetchRemoteVersions fetches versions from the configured download sources
 func fetchRemoteVersions() ([]GoVersion, error) {
-	url := config.DownloadURLPrefix + "?mode=json&include=all"
-
-	// Create request with timeout
-	client := &http.Client{Timeout: 30 * time.Second}
-	resp, err := client.Get(url)
+	apiResponse, err := FetchReleases()
 	if err != nil {
-		return nil, fmt.Errorf("failed to fetch remote versions: %w", err)
+		return nil, err
 	}
-	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+	return flattenReleases(apiResponse), nil
+}
 
-	body, err := io.ReadAll(resp.Body)
+// FetchReleases fetches the complete release index (all versions with all of their
+// files) in the format served by go.dev/dl/?mode=json&include=all, newest release
+// first. The configured download sources are tried in order until one of them answers.
+func FetchReleases() ([]GoVersionResponse, error) {
+	sources, err := source.Configured()
 	if err != nil {
-		return nil, fmt.Errorf("failed to read response body: %w", err)
+		return nil, err
 	}
 
-	// Parse the complete API response
-	var apiResponse []GoVersionResponse
-	if err := json.Unmarshal(body, &apiResponse); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal JSON response: %w", err)
+	var errs []error
+	for _, src := range sources {
+		releases, err := src.Releases()
+		if err == nil {
+			// Not every source lists releases in order, but callers rely on it
+			sort.SliceStable(releases, func(i, j int) bool {
+				return IsValid(releases[i].Version) && IsValid(releases[j].Version) &&
+					Compare(releases[i].Version, releases[j].Version) > 0
+			})
+			return releases, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", src.Name(), err))
 	}
+	return nil, errors.Join(errs...)
+}
 
+// flattenReleases converts the release index into one GoVersion per downloadable file.
+func flattenReleases(apiResponse []GoVersionResponse) []GoVersion {
 	// Convert to simplified GoVersion slice
 	var versions []GoVersion
 	for _, response := range apiResponse {
@@ -170,15 +188,105 @@ func fetchRemoteVersions() ([]GoVersion, error) {
 			}
 
 			versions = append(versions, GoVersion{
-				Version: response.Version,
-				Stable:  response.Stable,
-				OS:      file.OS,
-				Arch:    file.Arch,
+				Version:  response.Version,
+				Stable:   response.Stable,
+				OS:       file.OS,
+				Arch:     file.Arch,
+				Filename: file.Filename,
+				SHA256:   file.SHA256,
+				Size:     file.Size,
 			})
 		}
 	}
 
-	return versions, nil
+	return versions
+}
+
+// LookupArchive returns the download metadata (filename, checksum and size) of
+// the archive for the given version and platform. Depending on the source this is a
+// .tar.gz archive or a toolchain module zip.
+func LookupArchive(version, goOS, goARCH string) (GoVersionFile, error) {
+	versions, err := GetRemoteVersions()
+	if err != nil {
+		return GoVersionFile{}, err
+	}
+
+	file, ok, err := findArchive(versions, version, goOS, goARCH)
+	if err != nil {
+		return GoVersionFile{}, err
+	}
+	if !ok {
+		// A fresh release may not be in the cache yet: consult the remote index directly
+		versions, err = fetchRemoteVersions()
+		if err != nil {
+			return GoVersionFile{}, err
+		}
+		NewVersionCache().Save(versions)
+		if file, ok, err = findArchive(versions, version, goOS, goARCH); err != nil {
+			return GoVersionFile{}, err
+		}
+	}
+
+	if !ok {
+		if platforms := archivePlatforms(versions, version); len(platforms) > 0 {
+			return GoVersionFile{}, fmt.Errorf("no archive of %s for %s/%s found in the remote version index (available: %s)", version, goOS, goARCH, strings.Join(platforms, ", "))
+		}
+		return GoVersionFile{}, fmt.Errorf("no archive of %s for %s/%s found in the remote version index", version, goOS, goARCH)
+	}
+	return file, nil
+}
+
+// archivePlatforms lists the os/arch platforms with an archive of version in versions.
+func archivePlatforms(versions []GoVersion, version string) []string {
+	var platforms []string
+	for _, v := range versions {
+		if v.Version != version || (!strings.HasSuffix(v.Filename, ".tar.gz") && !strings.HasSuffix(v.Filename, ".zip")) {
+			continue
+		}
+		platforms = append(platforms, v.OS+"/"+v.Arch)
+	}
+	sort.Strings(platforms)
+	return slices.Compact(platforms)
+}
+
+// findArchive looks up the archive of version for the given platform in versions.
+// The filename and checksum are later used as path components, so an entry whose
+// filename is not exactly the expected archive name for version and platform, or
+// whose checksum is not a SHA-256 digest, is rejected.
+func findArchive(versions []GoVersion, version, goOS, goARCH string) (GoVersionFile, bool, error) {
+	for _, v := range versions {
+		if v.Version != version || v.OS != goOS || v.Arch != goARCH {
+			continue
+		}
+		if !strings.HasSuffix(v.Filename, ".tar.gz") && !strings.HasSuffix(v.Filename, ".zip") {
+			continue // e.g. macOS .pkg installers
+		}
+		if !isArchiveName(v.Filename, version, goOS, goARCH) {
+			return GoVersionFile{}, false, fmt.Errorf("remote version index lists unexpected archive %q for %s %s/%s", v.Filename, version, goOS, goARCH)
+		}
+		if v.SHA256 != "" && !cache.ValidSHA256(v.SHA256) {
+			return GoVersionFile{}, false, fmt.Errorf("remote version index lists invalid checksum %q for %s", v.SHA256, v.Filename)
+		}
+		return GoVersionFile{
+			Filename: v.Filename,
+			OS:       v.OS,
+			Arch:     v.Arch,
+			Version:  v.Version,
+			SHA256:   strings.ToLower(v.SHA256),
+			Size:     v.Size,
+			Kind:     "archive",
+		}, true, nil
+	}
+	return GoVersionFile{}, false, nil
+}
+
+// isArchiveName reports whether filename is the name of the archive of version for
+// goOS/goARCH: go<version>.<os>-<arch>.tar.gz or .zip, or the toolchain module zip
+// served by a Go module proxy.
+func isArchiveName(filename, version, goOS, goARCH string) bool {
+	base := version + "." + goOS + "-" + goARCH
+	return filename == base+".tar.gz" || filename == base+".zip" ||
+		filename == source.ToolchainFile(version, goOS, goARCH).Filename
 }
 
 // GetLatestGoVersion fetches the latest stable Go version from the official Go website.
@@ -197,6 +305,57 @@ func GetLatestGoVersion() (string, error) {
 	return "", fmt.Errorf("no stable Go version found")
 }
 
+// GetLatestUnstableGoVersion returns the newest Go release, counting prereleases
+// (release candidates and betas). While a new Go release is in its RC phase this is
+// the newest prerelease; otherwise it is the latest stable version.
+func GetLatestUnstableGoVersion() (string, error) {
+	versions, err := GetRemoteVersions()
+	if err != nil {
+		return "", err
+	}
+
+	latest := ""
+	for _, v := range versions {
+		if IsValid(v.Version) && (latest == "" || Compare(v.Version, latest) > 0) {
+			latest = v.Version
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("no Go version found")
+	}
+	return latest, nil
+}
+
+// LatestPatch returns the newest stable release of the major version (e.g. "1.22")
+// that is available for the given platform.
+func LatestPatch(major, goOS, goARCH string) (string, error) {
+	versions, err := GetStableGoVersions()
+	if err != nil {
+		return "", err
+	}
+
+	latest := latestPatch(versions, major, goOS, goARCH)
+	if latest == "" {
+		return "", fmt.Errorf("no stable release of Go %s found for %s/%s", major, goOS, goARCH)
+	}
+	return latest, nil
+}
+
+// latestPatch returns the newest version in versions of the major version for the given platform.
+func latestPatch(versions []GoVersion, major, goOS, goARCH string) string {
+	latest := ""
+	for _, v := range versions {
+		if v.OS != goOS || v.Arch != goARCH || !IsValid(v.Version) || MajorVersion(v.Version) != major {
+			continue
+		}
+		if latest == "" || Compare(v.Version, latest) > 0 {
+			latest = v.Version
+		}
+	}
+	return latest
+}
+
 // SwitchToVersion removes the existing CurrentSymlink and creates a new one.
 func SwitchToVersion(version string) error {
 	// Basic input validation: avoid path separators in version
@@ -206,17 +365,19 @@ func SwitchToVersion(version string) error {
 
 	// Ensure target exists and looks like an installed Go distribution
 	targetPath := filepath.Join(config.VersionsDir, version, "go") // Symlink to the 'go' directory inside the version
-	if fi, err := os.Stat(targetPath); err != nil {
+	if _, err := os.Stat(targetPath); err != nil {
 		return fmt.Errorf("version %s is not installed at %s: %w", version, targetPath, err)
-	} else if !fi.IsDir() {
-		return fmt.Errorf("version target is not a directory: %s", targetPath)
+	}
+	if err := ValidateGoRoot(targetPath); err != nil {
+		return fmt.Errorf("version %s is not usable: %w", version, err)
 	}
 
-	// Ensure the go binary exists under targetPath/bin/go
-	goBin := filepath.Join(targetPath, "bin", "go")
-	if _, err := os.Stat(goBin); err != nil {
-		return fmt.Errorf("go binary not found for version %s at %s: %w", version, goBin, err)
+	// Serialize with other sgv processes switching versions or writing env files
+	l, err := lock.Global()
+	if err != nil {
+		return err
 	}
+	defer l.Release()
 
 	// Check existing CurrentSymlink: if it exists, ensure it's a symlink; don't remove arbitrary files
 	if info, err := os.Lstat(config.CurrentSymlink); err == nil {
@@ -246,6 +407,24 @@ func SwitchToVersion(version string) error {
 	return nil
 }
 
+// ValidateGoRoot checks that goroot is a directory that looks like a Go distribution,
+// i.e. that it contains the go binary under bin/go.
+func ValidateGoRoot(goroot string) error {
+	if fi, err := os.Stat(goroot); err != nil {
+		return fmt.Errorf("failed to stat %s: %w", goroot, err)
+	} else if !fi.IsDir() {
+		return fmt.Errorf("version target is not a directory: %s", goroot)
+	}
+
+	goBin := filepath.Join(goroot, "bin", "go")
+	if fi, err := os.Stat(goBin); err != nil {
+		return fmt.Errorf("go binary not found at %s: %w", goBin, err)
+	} else if !fi.Mode().IsRegular() {
+		return fmt.Errorf("go binary is not a regular file: %s", goBin)
+	}
+	return nil
+}
+
 // GetStableGoVersions fetches all stable Go versions from the official Go website.
 func GetStableGoVersions() ([]GoVersion, error) {
 	remoteVersions, err := GetRemoteVersions()
@@ -1,8 +1,11 @@
 package version
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 
@@ -20,6 +23,10 @@ func setupTemp(t *testing.T) string {
 }
 
 func TestSwitchToVersion(t *testing.T) {
+	originalLocksDir := config.LocksDir
+	config.LocksDir = filepath.Join(setupTemp(t), "locks")
+	t.Cleanup(func() { config.LocksDir = originalLocksDir })
+
 	t.Run("success", func(t *testing.T) {
 		tmp := setupTemp(t)
 
@@ -119,3 +126,174 @@ func TestSwitchToVersion(t *testing.T) {
 		}
 	})
 }
+
+func TestFetchReleasesFailsOver(t *testing.T) {
+	down := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer down.Close()
+	up := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"version":"go1.22.1","stable":true,"files":[]}]`))
+	}))
+	defer up.Close()
+
+	original := config.DownloadMirrors
+	t.Cleanup(func() { config.DownloadMirrors = original })
+
+	config.DownloadMirrors = []string{down.URL + "/", up.URL + "/"}
+	releases, err := FetchReleases()
+	if err != nil {
+		t.Fatalf("FetchReleases failed: %v", err)
+	}
+	if len(releases) != 1 || releases[0].Version != "go1.22.1" {
+		t.Errorf("unexpected releases %+v", releases)
+	}
+
+	config.DownloadMirrors = []string{down.URL + "/"}
+	if _, err := FetchReleases(); err == nil {
+		t.Errorf("expected error when every mirror fails")
+	}
+}
+
+func TestLatestPatch(t *testing.T) {
+	versions := []GoVersion{
+		{Version: "go1.22.6", OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.10", OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.11", OS: "darwin", Arch: "arm64"},
+		{Version: "go1.23.2", OS: "linux", Arch: "amd64"},
+		{Version: "go1.22", OS: "linux", Arch: "amd64"},
+	}
+
+	tests := []struct {
+		major, goOS, goARCH string
+		want                string
+	}{
+		{"1.22", "linux", "amd64", "go1.22.10"},
+		{"1.22", "darwin", "arm64", "go1.22.11"},
+		{"1.23", "linux", "amd64", "go1.23.2"},
+		{"1.21", "linux", "amd64", ""},
+	}
+	for _, tt := range tests {
+		if got := latestPatch(versions, tt.major, tt.goOS, tt.goARCH); got != tt.want {
+			t.Errorf("latestPatch(%s, %s/%s) = %q, want %q", tt.major, tt.goOS, tt.goARCH, got, tt.want)
+		}
+	}
+}
+
+func TestResolveKeyword(t *testing.T) {
+	versions := []GoVersion{
+		{Version: "go1.23.2", OS: "linux", Arch: "amd64"},
+		{Version: "go1.23.1", OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.8", OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.7", OS: "linux", Arch: "amd64"},
+		{Version: "go1.21.13", OS: "linux", Arch: "amd64"},
+		{Version: "go1.24.0", OS: "darwin", Arch: "arm64"},
+	}
+
+	tests := []struct {
+		keyword, goOS string
+		want          string
+	}{
+		{"stable", "linux", "go1.23.2"},
+		{"oldstable", "linux", "go1.22.8"},
+		{"1.21.latest", "linux", "go1.21.13"},
+		{"go1.22.latest", "linux", "go1.22.8"},
+		{"stable", "darwin", "go1.24.0"},
+		{"oldstable", "darwin", ""},
+		{"1.20.latest", "linux", ""},
+	}
+	for _, tt := range tests {
+		got, err := resolveKeyword(versions, tt.keyword, tt.goOS, map[string]string{"linux": "amd64", "darwin": "arm64"}[tt.goOS])
+		if got != tt.want || (err != nil) != (tt.want == "") {
+			t.Errorf("resolveKeyword(%q, %s) = %q, %v; want %q", tt.keyword, tt.goOS, got, err, tt.want)
+		}
+	}
+
+	for _, s := range []string{"stable", "oldstable", "1.22.latest", "go1.22.latest"} {
+		if !IsKeyword(s) {
+			t.Errorf("IsKeyword(%q) = false", s)
+		}
+	}
+	for _, s := range []string{"latest", "1.22", ".latest", "1.22.6.latest", "prod"} {
+		if IsKeyword(s) {
+			t.Errorf("IsKeyword(%q) = true", s)
+		}
+	}
+}
+
+func TestInstalledLines(t *testing.T) {
+	versions := []GoVersion{
+		{Version: "go1.23.2", Stable: true, OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.8", Stable: true, OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.6", Stable: true, OS: "linux", Arch: "amd64"},
+		{Version: "go1.22.9", Stable: true, OS: "darwin", Arch: "arm64"},
+		{Version: "go1.21.13", Stable: true, OS: "linux", Arch: "amd64"},
+		{Version: "go1.24rc1", Stable: false, OS: "linux", Arch: "amd64"},
+	}
+	installed := []string{"go1.22.1", "go1.23.2", "go1.22.6", "go1.24rc1", "go1.21.0", "go1.19.13", "gotip-1a2b3c4d5e"}
+
+	lines := InstalledLines(installed, versions, "linux", "amd64")
+	want := []struct {
+		minor     string
+		installed []string
+		latest    string
+		outdated  bool
+		supported bool
+	}{
+		{"1.24", []string{"go1.24rc1"}, "", false, true},
+		{"1.23", []string{"go1.23.2"}, "go1.23.2", false, true},
+		{"1.22", []string{"go1.22.6", "go1.22.1"}, "go1.22.8", true, true},
+		{"1.21", []string{"go1.21.0"}, "go1.21.13", true, false},
+		{"1.19", []string{"go1.19.13"}, "", false, false},
+	}
+	if len(lines) != len(want) {
+		t.Fatalf("InstalledLines returned %d lines, want %d: %+v", len(lines), len(want), lines)
+	}
+	for i, w := range want {
+		l := lines[i]
+		if l.Minor != w.minor || !reflect.DeepEqual(l.Installed, w.installed) || l.Latest != w.latest || l.Outdated() != w.outdated || l.Supported != w.supported {
+			t.Errorf("line %d = %+v (outdated %v), want %+v", i, l, l.Outdated(), w)
+		}
+	}
+
+	if got := lines[2].Superseded(); !reflect.DeepEqual(got, []string{"go1.22.6", "go1.22.1"}) {
+		t.Errorf("Superseded() = %v", got)
+	}
+	if got := lines[1].Superseded(); len(got) != 0 {
+		t.Errorf("an up-to-date line should supersede nothing, got %v", got)
+	}
+}
+
+func TestFindArchiveRejectsUnexpectedEntries(t *testing.T) {
+	const sha = "9e2f2a4031b215922aa21a3695e30bbfa1f7707597834287415dbc862c6a3251"
+	entry := func(filename, sum string) []GoVersion {
+		return []GoVersion{
+			{Version: "go1.22.1", OS: "darwin", Arch: "amd64", Filename: "go1.22.1.darwin-amd64.pkg"},
+			{Version: "go1.22.1", OS: "darwin", Arch: "amd64", Filename: filename, SHA256: sum},
+		}
+	}
+
+	for _, tt := range []struct{ filename, sum string }{
+		{"go1.22.1.darwin-amd64.tar.gz", sha},
+		{"go1.22.1.darwin-amd64.zip", ""},
+		{"v0.0.1-go1.22.1.darwin-amd64.zip", ""},
+	} {
+		file, ok, err := findArchive(entry(tt.filename, tt.sum), "go1.22.1", "darwin", "amd64")
+		if err != nil || !ok || file.Filename != tt.filename {
+			t.Errorf("findArchive(%q) = %+v, %v, %v", tt.filename, file, ok, err)
+		}
+	}
+
+	for _, tt := range []struct{ filename, sum string }{
+		{"../../../.bashrc.tar.gz", sha},
+		{"go1.22.2.darwin-amd64.tar.gz", sha},
+		{"go1.22.1.linux-amd64.tar.gz", sha},
+		{"sub/go1.22.1.darwin-amd64.tar.gz", sha},
+		{"go1.22.1.darwin-amd64.tar.gz", "../../evil"},
+		{"go1.22.1.darwin-amd64.tar.gz", sha[:60]},
+	} {
+		if _, _, err := findArchive(entry(tt.filename, tt.sum), "go1.22.1", "darwin", "amd64"); err == nil {
+			t.Errorf("findArchive(%q, %q) should reject the entry", tt.filename, tt.sum)
+		}
+	}
+}